@@ -0,0 +1,283 @@
+// Package app 把各个包组装成命令行程序：参数和配置文件的解析、对局、复盘、导出以及各种离线工具
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/engine"
+	"github.com/CuteReimu/gobang/record"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	sideHuman = "human"
+	sideRobot = "robot"
+	uiGUI     = "gui"
+	uiTUI     = "tui"
+)
+
+// Config 是游戏设置，可以写在JSON配置文件中，命令行参数优先于配置文件
+type Config struct {
+	Black     string  `json:"black"`
+	White     string  `json:"white"`
+	Rule      string  `json:"rule"`
+	Size      int     `json:"size"`
+	UI        string  `json:"ui"`
+	Level     string  `json:"level"`
+	Depth     int     `json:"depth"`
+	Width     int     `json:"width"`
+	Kill      int     `json:"kill"`
+	Blunder   float64 `json:"blunder"`
+	MultiPV   int     `json:"multipv"`
+	Ponder    bool    `json:"ponder"`
+	Resign    bool    `json:"resign"`
+	Book      string  `json:"book"`
+	NoBook    bool    `json:"nobook"`
+	Weights   string  `json:"weights"`
+	Handicap  int     `json:"handicap"`
+	Retries   int     `json:"retries"`
+	Time      string  `json:"time"`
+	Increment string  `json:"increment"`
+	Stats     bool    `json:"stats"`
+	Load      string  `json:"load"`
+	Save      string  `json:"save"`
+
+	// 以下由Validate根据上面的字段得出
+	rule        board.Rule
+	robotLevel  engine.Difficulty
+	time        time.Duration
+	increment   time.Duration
+	evalWeights *engine.EvalWeights
+	loaded      *record.Record // 用-load读取的棋谱
+}
+
+// DefaultConfig 返回默认设置：机器人执黑，人类执白，自由规则的15路棋盘，窗口界面
+func DefaultConfig() *Config {
+	return &Config{
+		Black:   sideRobot,
+		White:   sideHuman,
+		Rule:    board.Freestyle.String(),
+		Size:    15,
+		UI:      uiGUI,
+		Level:   engine.Hard.String(),
+		Kill:    -1,
+		Blunder: -1,
+		MultiPV: 1,
+		Ponder:  true,
+		Resign:  true,
+		Book:    "book.txt",
+		Retries: 3,
+		Stats:   true,
+	}
+}
+
+// RegisterFlags 把每个设置注册成fs的一个参数
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Black, "black", c.Black, "who plays black: human or robot")
+	fs.StringVar(&c.White, "white", c.White, "who plays white: human or robot")
+	fs.StringVar(&c.Rule, "rule", c.Rule, fmt.Sprintf("rule set, one of %v", board.RuleNames))
+	fs.IntVar(&c.Size, "size", c.Size, "board size")
+	fs.StringVar(&c.UI, "ui", c.UI, "user interface: gui or tui")
+	fs.StringVar(&c.Level, "level", c.Level, fmt.Sprintf("robot difficulty, one of %v", engine.DifficultyNames))
+	fs.IntVar(&c.Depth, "depth", c.Depth, "robot search depth, 0 means decided by -level")
+	fs.IntVar(&c.Width, "width", c.Width, "robot search width, 0 means decided by -level")
+	fs.IntVar(&c.Kill, "kill", c.Kill, "robot kill search depth, -1 means decided by -level")
+	fs.Float64Var(&c.Blunder, "blunder", c.Blunder, "probability that the robot plays a random good move, -1 means decided by -level")
+	fs.IntVar(&c.MultiPV, "multipv", c.MultiPV, "number of lines the robot analyzes")
+	fs.BoolVar(&c.Ponder, "ponder", c.Ponder, "let the robot think during the opponent's turn")
+	fs.BoolVar(&c.Resign, "resign", c.Resign, "let the robot resign when it sees a forced loss")
+	fs.StringVar(&c.Book, "book", c.Book, "opening book file, ignored if it does not exist")
+	fs.BoolVar(&c.NoBook, "nobook", c.NoBook, "do not use the opening book")
+	fs.StringVar(&c.Weights, "weights", c.Weights, "load evaluation weights from this JSON file")
+	fs.IntVar(&c.Handicap, "handicap", c.Handicap, "number of extra stones the human player places at the start")
+	fs.IntVar(&c.Retries, "retries", c.Retries, "how many times a player may retry after an illegal move before forfeiting")
+	fs.StringVar(&c.Time, "time", c.Time, "thinking time of each side, e.g. 10m; empty means unlimited")
+	fs.StringVar(&c.Increment, "increment", c.Increment, "time added after each move, e.g. 5s")
+	fs.BoolVar(&c.Stats, "stats", c.Stats, "show search statistics in the window")
+	fs.StringVar(&c.Load, "load", c.Load, "resume the game saved in this record file, or start from a position such as 15;renju;w;h8j10;i9; its rule, size and handicap are used")
+	fs.StringVar(&c.Save, "save", c.Save, "save the game record to this file after every move")
+}
+
+// ReadFile 从JSON配置文件读取设置，文件中没有的设置保持不变
+func (c *Config) ReadFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("config file %s: %w", name, err)
+	}
+	return nil
+}
+
+// Validate 检查全部设置，读取-load的棋谱和-weights的权重，返回的错误包括所有不合法的设置
+func (c *Config) Validate() error {
+	var errs []error
+	humans := c.humans()
+	switch c.UI {
+	case uiGUI:
+		if humans > 1 {
+			errs = append(errs, fmt.Errorf("the %s supports at most one human player, use -ui %s for two", uiGUI, uiTUI))
+		}
+	case uiTUI:
+	default:
+		errs = append(errs, fmt.Errorf("invalid ui %q: want %s or %s", c.UI, uiGUI, uiTUI))
+	}
+	var err error
+	if c.rule, err = board.ParseRule(c.Rule); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Load != "" { // 规则和棋盘大小以棋谱为准
+		if rec, err := loadRecord(c.Load, c.rule); err != nil {
+			errs = append(errs, err)
+		} else {
+			c.rule, c.Size, c.loaded = rec.Rule, rec.Size, rec
+		}
+	}
+	if c.robotLevel, err = engine.ParseDifficulty(c.Level); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Size < 5 || c.Size > 26 {
+		errs = append(errs, fmt.Errorf("invalid size %d: want 5 to 26", c.Size))
+	}
+	if c.Depth < 0 || c.Width < 0 {
+		errs = append(errs, fmt.Errorf("depth and width must not be negative, got %d and %d", c.Depth, c.Width))
+	}
+	if c.Kill < -1 {
+		errs = append(errs, fmt.Errorf("invalid kill %d: want -1 or more", c.Kill))
+	}
+	if c.Blunder != -1 && (c.Blunder < 0 || c.Blunder > 1) {
+		errs = append(errs, fmt.Errorf("invalid blunder %g: want -1 or 0 to 1", c.Blunder))
+	}
+	if c.MultiPV < 1 {
+		errs = append(errs, fmt.Errorf("invalid multipv %d: want at least 1", c.MultiPV))
+	}
+	if c.Handicap < 0 {
+		errs = append(errs, fmt.Errorf("invalid handicap %d: must not be negative", c.Handicap))
+	} else if c.Handicap > 0 && humans != 1 {
+		errs = append(errs, errors.New("handicap needs exactly one human player"))
+	}
+	if c.Retries < 0 {
+		errs = append(errs, fmt.Errorf("invalid retries %d: must not be negative", c.Retries))
+	}
+	if c.time, err = parseOptionalDuration("time", c.Time); err != nil {
+		errs = append(errs, err)
+	}
+	if c.increment, err = parseOptionalDuration("increment", c.Increment); err != nil {
+		errs = append(errs, err)
+	}
+	c.evalWeights = engine.DefaultEvalWeights()
+	if c.Weights != "" {
+		if c.evalWeights, err = engine.LoadEvalWeights(c.Weights); err != nil {
+			errs = append(errs, fmt.Errorf("weights: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func parseOptionalDuration(name, s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q: want a duration such as 10m or 5s", name, s)
+	}
+	return d, nil
+}
+
+func (c *Config) humans() int {
+	n := 0
+	for _, side := range []string{c.Black, c.White} {
+		if side == sideHuman {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *Config) side(color board.Color) string {
+	if color == board.Black {
+		return c.Black
+	}
+	return c.White
+}
+
+// 读取棋谱，PSQ没有记录规则，用rule代替。name也可以是"15;renju;w;h8j10;i9"这样的局面
+func loadRecord(name string, rule board.Rule) (*record.Record, error) {
+	if isPosition(name) {
+		pos, err := board.ParsePosition(name)
+		if err != nil {
+			return nil, err
+		}
+		return record.FromPosition(pos)
+	}
+	rec, err := record.Load(name)
+	if err == nil && strings.EqualFold(filepath.Ext(name), ".psq") {
+		rec.Rule = rule
+	}
+	return rec, err
+}
+
+// 局面的文字里有4个分号，文件名一般不会有
+func isPosition(s string) bool {
+	return strings.Count(s, ";") == 4
+}
+
+// 棋谱中显示的玩家名字
+func (c *Config) playerName(color board.Color) string {
+	if c.side(color) == sideRobot {
+		return fmt.Sprintf("%s (%s)", sideRobot, c.Level)
+	}
+	return sideHuman
+}
+
+// 窗口中按S保存、按L读取的棋谱文件
+func (c *Config) recordFile() string {
+	switch {
+	case c.Save != "":
+		return c.Save
+	case c.Load != "" && !isPosition(c.Load):
+		return c.Load
+	}
+	return "game.json"
+}
+
+// 用当前的设置创建记录棋谱的Recorder，继续下-load的棋谱时沿用其中的着法
+func (c *Config) newRecorder() *record.Recorder {
+	rec := record.Record{}
+	if c.loaded != nil {
+		rec = *c.loaded
+	}
+	rec.Black, rec.White = c.playerName(board.Black), c.playerName(board.White)
+	rec.Time, rec.Increment = record.Duration(c.time), record.Duration(c.increment)
+	return record.NewRecorder(rec)
+}
+
+func (c *Config) engineConfig() engine.Config {
+	return engine.Config{Level: c.robotLevel, Depth: c.Depth, Width: c.Width, Kill: c.Kill, Blunder: c.Blunder, Weights: c.evalWeights}
+}
+
+func (c *Config) newRobot(color board.Color) *engine.Robot {
+	rp := c.engineConfig().NewPlayer(color)
+	rp.MultiPV = c.MultiPV
+	rp.Ponder = c.Ponder
+	rp.Resign = c.Resign
+	if !c.NoBook {
+		if book, err := engine.LoadOpeningBook(c.Book); err == nil {
+			rp.Book = book
+		} else if !os.IsNotExist(err) {
+			engine.Log.Warn(err.Error())
+		}
+	}
+	return rp
+}
@@ -0,0 +1,248 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/engine"
+	"github.com/CuteReimu/gobang/game"
+	"github.com/CuteReimu/gobang/record"
+	"github.com/CuteReimu/gobang/ui"
+	"github.com/hajimehoshi/ebiten/v2"
+	"io"
+	"os"
+)
+
+// Play 按-ui在窗口或者终端里下一局，replay为true时改为在窗口里复盘-load读取的对局，结束后返回
+func (c *Config) Play(replay bool) error {
+	if err := c.checkLoaded(); err != nil {
+		return err
+	}
+	if c.UI == uiTUI {
+		return c.playTerminal(os.Stdin, os.Stdout)
+	}
+	return c.playWindow(replay)
+}
+
+// 在终端里下一局，人类玩家从in输入着法，棋盘和日志写到out
+func (c *Config) playTerminal(in io.Reader, out io.Writer) error {
+	g, recorder, save, opts := c.newGame(out)
+	scanner := bufio.NewScanner(in)
+	var players []game.Player
+	var tps []*ui.TerminalPlayer
+	for _, color := range []board.Color{board.Black, board.White} {
+		if c.side(color) == sideRobot {
+			players = append(players, c.newRobot(color))
+		} else {
+			tp := ui.NewTerminalPlayer(color, scanner, out)
+			tps = append(tps, tp)
+			players = append(players, tp)
+			opts.Handicapped = tp
+		}
+	}
+	opts.AfterMove = func(pl game.Player, p board.Point) {
+		printAnalysis(out, pl)
+		recordEval(recorder, pl)
+		save()
+	}
+	game.Run(g, players, opts)
+	save()
+	for _, tp := range tps[:min(len(tps), 1)] {
+		tp.Render()
+	}
+	return nil
+}
+
+// 打开窗口下一局或者复盘，窗口关闭后返回
+func (c *Config) playWindow(replay bool) error {
+	window := ui.NewSwitcher(nil)
+	if replay {
+		if c.loaded == nil {
+			return errors.New("-replay needs a game from -load")
+		}
+		t, err := c.loadedTree()
+		if err != nil {
+			return err
+		}
+		window.Set(c.newReplayViewer(window, t))
+	} else {
+		window.Set(c.startGUI(window))
+	}
+	ebiten.SetWindowSize(ui.WindowSize())
+	ebiten.SetWindowTitle("gobang")
+	return ebiten.RunGame(window)
+}
+
+// 检查-load的棋谱能不能在当前规则下摆出来
+func (c *Config) checkLoaded() error {
+	if c.loaded == nil {
+		return nil
+	}
+	if err := c.loaded.Restore(game.NewGame(c.rule)); err != nil {
+		return fmt.Errorf("%s: %w", c.Load, err)
+	}
+	return nil
+}
+
+// 用当前的设置创建对局和记录棋谱的Recorder，日志写到out，save在有-save时保存棋谱，opts里还没有设置玩家相关的选项
+func (c *Config) newGame(out io.Writer) (*game.Game, *record.Recorder, func(), game.Options) {
+	g := game.NewGame(c.rule)
+	g.Subscribe(game.NewLogger(out))
+	recorder := c.newRecorder()
+	g.Subscribe(recorder)
+	save := func() {
+		if c.Save != "" {
+			if err := recorder.Record().Save(c.Save); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+	opts := game.Options{Time: c.time, Increment: c.increment, Handicap: c.Handicap, Retries: c.Retries}
+	if loaded := c.loaded; loaded != nil {
+		opts.Setup = func(g *game.Game) {
+			loaded.Restore(g) // 开始前已经检查过，不会出错
+		}
+	}
+	return g, recorder, save, opts
+}
+
+// 窗口里按H能选到的最多让子数，再按一次回到不让子
+const maxHandicap = 4
+
+// 在另一个goroutine中开始一局窗口里的对局，返回要显示的窗口。对局结束后按V在sw中换成复盘窗口
+func (c *Config) startGUI(sw *ui.Switcher) ebiten.Game {
+	g, recorder, save, opts := c.newGame(os.Stdout)
+	var players []game.Player
+	var robots []*engine.Robot
+	var hp *ui.HumanPlayer
+	for _, color := range []board.Color{board.Black, board.White} {
+		if c.side(color) == sideRobot {
+			rp := c.newRobot(color)
+			robots = append(robots, rp)
+			players = append(players, rp)
+		} else {
+			hp = ui.NewHumanPlayer(color)
+			players = append(players, hp)
+			opts.Handicapped = hp
+		}
+	}
+	var window ebiten.Game
+	var panel interface {
+		SetInfo(string)
+		SetStatus(string)
+	}
+	onSave := func() {
+		if err := recorder.Record().Save(c.recordFile()); err != nil {
+			panel.SetStatus(err.Error())
+		} else {
+			panel.SetStatus("saved to " + c.recordFile())
+		}
+	}
+	onReview := func() {
+		sw.Set(c.newReplayViewer(sw, recorder.Record().Tree()))
+	}
+	if hp != nil {
+		hp.OnDifficulty = func(d engine.Difficulty) {
+			for _, rp := range robots {
+				rp.SetDifficulty(d)
+			}
+		}
+		hp.OnLoad = func() error {
+			rec, err := loadRecord(c.recordFile(), c.rule)
+			if err != nil {
+				return err
+			}
+			if rec.Rule != c.rule {
+				return fmt.Errorf("%s: rule %s does not match %s", c.recordFile(), rec.Rule, c.rule)
+			}
+			if err := rec.Restore(game.NewGame(c.rule)); err != nil {
+				return fmt.Errorf("%s: %w", c.recordFile(), err)
+			}
+			rec.Black, rec.White = c.playerName(board.Black), c.playerName(board.White)
+			recorder.Reset(*rec)
+			rec.Restore(g)
+			hp.SetStatus("loaded " + c.recordFile())
+			return nil
+		}
+		if c.humans() == 1 {
+			hp.OnHandicap = func() {
+				c.Handicap = (c.Handicap + 1) % (maxHandicap + 1)
+				g.Start(hp.Color(), c.Handicap)
+				hp.SetStatus(fmt.Sprintf("new game, handicap %d", c.Handicap))
+			}
+		}
+		hp.OnSave = onSave
+		hp.OnReview = onReview
+		window, panel = hp, hp
+	} else {
+		hw := ui.NewHumanWatcher()
+		g.Subscribe(hw)
+		hw.OnSave = onSave
+		hw.OnReview = onReview
+		window, panel = hw, hw
+	}
+	opts.AfterMove = func(pl game.Player, p board.Point) {
+		printAnalysis(os.Stdout, pl)
+		recordEval(recorder, pl)
+		save()
+		if a, ok := pl.(engine.Analyzer); ok && a.LastAnalysis() != nil {
+			panel.SetInfo(a.LastAnalysis().Lines[0].String())
+			if c.Stats {
+				panel.SetStatus(a.LastAnalysis().Stats.String())
+			}
+		}
+	}
+	go func() {
+		game.Run(g, players, opts)
+		save()
+	}()
+	return window
+}
+
+// 创建复盘窗口，按B时从当前局面开始新的一局，轮到的一方是人类，另一方是机器人
+func (c *Config) newReplayViewer(sw *ui.Switcher, t *record.Tree) *ui.ReplayViewer {
+	v := ui.NewReplayViewer(t)
+	v.OnBranch = func(rec *record.Record) {
+		branch := c.branch(rec)
+		sw.Set(branch.startGUI(sw))
+	}
+	return v
+}
+
+// 返回从rec接着下的一局的设置，c本身不变，这样以后再按B或者V时用的还是原来的设置。
+// rec下过子或者本来就让子时按rec摆出局面，让子也以rec为准；从空棋盘开始时就是新的一局，按c的让子设置让给人类
+func (c *Config) branch(rec *record.Record) *Config {
+	g := game.NewGame(rec.Rule)
+	rec.Restore(g) // 是下过的棋谱的一部分，不会出错
+	branch := *c
+	branch.Black, branch.White = sideRobot, sideRobot
+	if g.WhoseTurn() == board.Black {
+		branch.Black = sideHuman
+	} else {
+		branch.White = sideHuman
+	}
+	branch.Rule, branch.rule, branch.Size = rec.Rule.String(), rec.Rule, rec.Size
+	branch.Load = "" // 按S时不要覆盖复盘的棋谱
+	branch.loaded = rec
+	if len(rec.Moves) == 0 && rec.Handicap == 0 {
+		branch.loaded = nil
+	} else {
+		branch.Handicap = rec.Handicap
+	}
+	return &branch
+}
+
+// 把机器人对刚才这一步的评分记进棋谱
+func recordEval(recorder *record.Recorder, pl game.Player) {
+	if a, ok := pl.(engine.Analyzer); ok && a.LastAnalysis() != nil {
+		recorder.SetEval(a.LastAnalysis().Lines[0].Value)
+	}
+}
+
+// 把机器人对刚才这一步的分析写到w
+func printAnalysis(w io.Writer, pl game.Player) {
+	if a, ok := pl.(engine.Analyzer); ok && a.LastAnalysis() != nil {
+		fmt.Fprint(w, a.LastAnalysis())
+	}
+}
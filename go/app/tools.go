@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/engine"
+	"github.com/CuteReimu/gobang/match"
+	"github.com/CuteReimu/gobang/record"
+	"github.com/CuteReimu/gobang/render"
+	"github.com/CuteReimu/gobang/ui"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Tune 用当前的规则以base为基础调参，base没有指定权重时从-weights的权重开始
+func (c *Config) Tune(base engine.Config, iterations, pairs int, out string) error {
+	if base.Weights == nil {
+		base.Weights = c.evalWeights
+	}
+	return match.Tune(base, base.Weights, iterations, max(pairs, 1), out, c.rule)
+}
+
+// Match 用当前的规则让两个引擎对弈games局
+func (c *Config) Match(engine1, engine2 engine.Config, games, concurrency int, test *match.SPRT) match.Result {
+	return match.Run(engine1, engine2, games, max(concurrency, 1), test, c.rule)
+}
+
+// BuildBook 用当前的引擎设置和规则生成开局库out，参数的含义见match.BuildOpeningBook
+func (c *Config) BuildBook(out, records string, selfPlay, plies int) error {
+	return match.BuildOpeningBook(out, records, selfPlay, plies, c.engineConfig(), c.rule)
+}
+
+// Analyze 让机器人分析棋谱或局面name中的每一步，结果写到w。有-save时把评分保存成新的棋谱
+func (c *Config) Analyze(name string, w io.Writer) error {
+	rec, err := loadRecord(name, c.rule)
+	if err != nil {
+		return err
+	}
+	board.Size = rec.Size
+	if err := match.Analyze(rec, c.engineConfig(), w); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if c.Save != "" {
+		return rec.Save(c.Save)
+	}
+	return nil
+}
+
+// Browse 在终端里浏览棋谱文件name中的开局树
+func Browse(name string, in io.Reader, out io.Writer) error {
+	t, err := record.LoadTree(name)
+	if err != nil {
+		return err
+	}
+	board.Size = t.Info.Size
+	return ui.BrowseTree(t, bufio.NewScanner(in), out)
+}
+
+// Export 把-load读取的对局画成图片name，.png是最后（或第move步）的局面，.gif是每一步，每步显示delay。
+// numbers和lastMove决定是否在棋子上写出步数、是否标出最后一步
+func (c *Config) Export(name string, numbers, lastMove bool, move int, delay time.Duration) error {
+	if c.loaded == nil {
+		return errors.New("-export needs a game or position from -load")
+	}
+	t, err := c.loadedTree()
+	if err != nil {
+		return err
+	}
+	frames := render.Frames(t)
+	if move >= 0 {
+		if move >= len(frames) {
+			return fmt.Errorf("-exportmove %d: the game has only %d moves", move, len(frames)-1)
+		}
+		frames = frames[:move+1]
+	}
+	for _, d := range frames {
+		d.Numbers, d.LastMove = numbers, lastMove
+	}
+	return render.Save(name, frames, delay)
+}
+
+// 返回-load读取的对局。SGF棋谱和Renlib开局库重新按树读取，这样能带上主变上的记号和评价
+func (c *Config) loadedTree() (*record.Tree, error) {
+	if ext := strings.ToLower(filepath.Ext(c.Load)); ext == ".sgf" || ext == ".lib" {
+		return record.LoadTree(c.Load)
+	}
+	return c.loaded.Tree(), nil
+}
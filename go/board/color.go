@@ -0,0 +1,78 @@
+package board
+
+import "fmt"
+
+// Color 是棋子的颜色
+type Color int8
+
+const (
+	Empty Color = iota // 空位
+	Black
+	White
+)
+
+func (c Color) String() string {
+	switch c {
+	case Empty:
+		return "无"
+	case Black:
+		return "黑"
+	case White:
+		return "白"
+	}
+	panic("unreachable")
+}
+
+// Symbol 返回在终端里显示的棋子符号
+func (c Color) Symbol() string {
+	switch c {
+	case Black:
+		return "●"
+	case White:
+		return "○"
+	}
+	panic("unreachable")
+}
+
+// LastSymbol 返回最后一步棋的棋子符号
+func (c Color) LastSymbol() string {
+	switch c {
+	case Black:
+		return "◆"
+	case White:
+		return "◎"
+	}
+	panic("unreachable")
+}
+
+// Conversion 返回对方的颜色
+func (c Color) Conversion() Color {
+	return 3 - c
+}
+
+// MarshalText 把颜色写成black、white或empty，用于棋谱等文件
+func (c Color) MarshalText() ([]byte, error) {
+	switch c {
+	case Empty:
+		return []byte("empty"), nil
+	case Black:
+		return []byte("black"), nil
+	case White:
+		return []byte("white"), nil
+	}
+	return nil, fmt.Errorf("invalid color %d", c)
+}
+
+func (c *Color) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "empty", "":
+		*c = Empty
+	case "black":
+		*c = Black
+	case "white":
+		*c = White
+	default:
+		return fmt.Errorf("invalid color %q, want black, white or empty", text)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Package board 定义棋盘上的坐标、棋子颜色和胜负规则，是其它包共用的基础。
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Size 是棋盘大小，需要在创建任何棋盘之前设置
+var Size = 15
+
+// Direction 是棋盘上的一个方向
+type Direction struct {
+	X, Y int
+}
+
+// FourDirections 是横、竖和两条斜线，每条线只取一个方向
+var FourDirections = []Direction{{-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+
+// EightDirections 是周围的八个方向
+var EightDirections = []Direction{{-1, 0}, {-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}}
+
+// Point 是棋盘上的交叉点，X是列，Y是行，都从0开始
+type Point struct {
+	X, Y int
+}
+
+// Move 返回沿dir方向走length格之后的点
+func (p Point) Move(dir Direction, length int) Point {
+	if length == 0 {
+		return p
+	}
+	return Point{p.X + dir.X*length, p.Y + dir.Y*length}
+}
+
+// CheckRange 返回这个点是否在棋盘内
+func (p Point) CheckRange() bool {
+	return p.X < Size && p.X >= 0 && p.Y < Size && p.Y >= 0
+}
+
+// NearMidThan 返回p是否比p2更靠近棋盘中心
+func (p Point) NearMidThan(p2 Point) bool {
+	return max(abs(p.X-Size/2), abs(p.Y-Size/2)) < max(abs(p2.X-Size/2), abs(p2.Y-Size/2))
+}
+
+// Distance 返回两点之间横、竖或斜向的距离
+func (p Point) Distance(p2 Point) int {
+	return max(abs(p.X-p2.X), abs(p.Y-p2.Y))
+}
+
+// Hash 返回这个点的序号，范围是0到Size*Size-1
+func (p Point) Hash() int {
+	return p.Y*Size + p.X
+}
+
+// String 把棋盘内的点写成"h8"，棋盘外的点写成"(x,y)"
+func (p Point) String() string {
+	if !p.CheckRange() {
+		return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+	}
+	return p.Notation(Size)
+}
+
+// Notation 返回size路棋盘上p点的标准写法，例如15路棋盘的天元是"h8"：列用字母从a开始，行从下往上数，从1开始
+func (p Point) Notation(size int) string {
+	return fmt.Sprintf("%c%d", 'a'+p.X, size-p.Y)
+}
+
+// ParseNotation 解析size路棋盘上"h8"这样的标准写法，字母不区分大小写
+func ParseNotation(s string, size int) (Point, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 2 || s[0] < 'a' || s[0] > 'z' {
+		return Point{}, fmt.Errorf("invalid point %q, want a letter and a number like h8", s)
+	}
+	row, err := strconv.Atoi(s[1:])
+	p := Point{X: int(s[0] - 'a'), Y: size - row}
+	if err != nil || p.X >= size || p.Y < 0 || p.Y >= size {
+		return Point{}, fmt.Errorf("invalid point %q, want a1 to %s", s, Point{X: size - 1, Y: 0}.Notation(size))
+	}
+	return p, nil
+}
+
+// MarshalText 把点写成和棋盘大小无关的"x,y"
+func (p Point) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", p.X, p.Y)), nil
+}
+
+func (p *Point) UnmarshalText(text []byte) error {
+	if _, err := fmt.Sscanf(string(text), "%d,%d", &p.X, &p.Y); err != nil {
+		return fmt.Errorf("invalid point %q, want x,y", text)
+	}
+	return nil
+}
+
+func max(x ...int) int {
+	m := x[0]
+	for i := 1; i < len(x); i++ {
+		if x[i] > m {
+			m = x[i]
+		}
+	}
+	return m
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,61 @@
+package board
+
+import "testing"
+
+func TestParseNotation(t *testing.T) {
+	tests := []struct {
+		s    string
+		size int
+		want Point
+		ok   bool
+	}{
+		{"h8", 15, Point{X: 7, Y: 7}, true},
+		{"a1", 15, Point{X: 0, Y: 14}, true},
+		{"o15", 15, Point{X: 14, Y: 0}, true},
+		{"a15", 15, Point{X: 0, Y: 0}, true},
+		{"o1", 15, Point{X: 14, Y: 14}, true},
+		{"O15", 15, Point{X: 14, Y: 0}, true},
+		{" h8 ", 15, Point{X: 7, Y: 7}, true},
+		{"s19", 19, Point{X: 18, Y: 0}, true},
+		{"p1", 15, Point{}, false},
+		{"a16", 15, Point{}, false},
+		{"a0", 15, Point{}, false},
+		{"h", 15, Point{}, false},
+		{"8h", 15, Point{}, false},
+		{"h8x", 15, Point{}, false},
+		{"", 15, Point{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseNotation(tt.s, tt.size)
+		if (err == nil) != tt.ok || tt.ok && got != tt.want {
+			t.Errorf("ParseNotation(%q, %d) = %v, %v; want %v, ok %t", tt.s, tt.size, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNotationRoundTrip(t *testing.T) {
+	for _, size := range []int{5, 15, 19, 26} {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				p := Point{X: x, Y: y}
+				s := p.Notation(size)
+				if got, err := ParseNotation(s, size); err != nil || got != p {
+					t.Errorf("size %d: %v written as %q reads back as %v, %v", size, p, s, got, err)
+				}
+			}
+		}
+	}
+}
+
+func TestPointText(t *testing.T) {
+	var p Point
+	if err := p.UnmarshalText([]byte("3,12")); err != nil || p != (Point{X: 3, Y: 12}) {
+		t.Errorf("UnmarshalText(3,12) = %v, %v", p, err)
+	}
+	if text, _ := p.MarshalText(); string(text) != "3,12" {
+		t.Errorf("MarshalText = %q, want 3,12", text)
+	}
+	if err := p.UnmarshalText([]byte("h8")); err == nil {
+		t.Error("UnmarshalText(h8) succeeded, want an error")
+	}
+}
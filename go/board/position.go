@@ -0,0 +1,128 @@
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Position 是一个局面：棋盘大小、规则、轮到哪一方和双方的棋子，可以写成紧凑的文字，例如"15;renju;w;h8j10;i9"，
+// 依次是棋盘大小、规则、轮到哪一方（b或w）、全部黑子、全部白子。坐标是Point.Notation的写法，
+// 连着写就是弈心那样的着法序列"h8i9j10"。用于命令行分析、测试局面和报告机器人的问题
+type Position struct {
+	Size  int
+	Rule  Rule
+	Turn  Color
+	Black []Point
+	White []Point
+}
+
+// PositionOf 按行从上到下、每行从左到右收集棋盘b上的棋子，同样的局面总是得到同样的文字
+func PositionOf(b [][]Color, rule Rule, turn Color) *Position {
+	pos := &Position{Size: len(b), Rule: rule, Turn: turn}
+	for y, row := range b {
+		for x, color := range row {
+			switch color {
+			case Black:
+				pos.Black = append(pos.Black, Point{X: x, Y: y})
+			case White:
+				pos.White = append(pos.White, Point{X: x, Y: y})
+			}
+		}
+	}
+	return pos
+}
+
+// ParsePosition 解析String写出的局面
+func ParsePosition(s string) (*Position, error) {
+	fields := strings.Split(strings.TrimSpace(s), ";")
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid position %q, want size;rule;side;black;white", s)
+	}
+	size, err := strconv.Atoi(fields[0])
+	if err != nil || size < 1 || size > 26 {
+		return nil, fmt.Errorf("invalid position %q: size must be between 1 and 26", s)
+	}
+	rule, err := ParseRule(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid position %q: %w", s, err)
+	}
+	pos := &Position{Size: size, Rule: rule}
+	switch fields[2] {
+	case "b":
+		pos.Turn = Black
+	case "w":
+		pos.Turn = White
+	default:
+		return nil, fmt.Errorf("invalid position %q: side to move must be b or w", s)
+	}
+	if pos.Black, err = ParseMoves(fields[3], size); err != nil {
+		return nil, fmt.Errorf("invalid position %q: %w", s, err)
+	}
+	if pos.White, err = ParseMoves(fields[4], size); err != nil {
+		return nil, fmt.Errorf("invalid position %q: %w", s, err)
+	}
+	seen := make(map[Point]bool)
+	for _, p := range append(append([]Point(nil), pos.Black...), pos.White...) {
+		if seen[p] {
+			return nil, fmt.Errorf("invalid position %q: %s is used twice", s, p.Notation(size))
+		}
+		seen[p] = true
+	}
+	return pos, nil
+}
+
+func (pos *Position) String() string {
+	turn := "b"
+	if pos.Turn == White {
+		turn = "w"
+	}
+	return fmt.Sprintf("%d;%s;%s;%s;%s", pos.Size, pos.Rule, turn, FormatMoves(pos.Black, pos.Size), FormatMoves(pos.White, pos.Size))
+}
+
+// Board 返回摆好棋子的棋盘
+func (pos *Position) Board() [][]Color {
+	b := make([][]Color, pos.Size)
+	for i := range b {
+		b[i] = make([]Color, pos.Size)
+	}
+	for _, p := range pos.Black {
+		b[p.Y][p.X] = Black
+	}
+	for _, p := range pos.White {
+		b[p.Y][p.X] = White
+	}
+	return b
+}
+
+// ParseMoves 解析"h8i9j10"这样连着写的坐标，坐标之间也可以有空格或逗号
+func ParseMoves(s string, size int) ([]Point, error) {
+	var points []Point
+	s = strings.ToLower(s)
+	for i := 0; i < len(s); {
+		if c := s[i]; c == ' ' || c == ',' {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		p, err := ParseNotation(s[i:j], size)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+		i = j
+	}
+	return points, nil
+}
+
+// FormatMoves 把坐标连着写成"h8i9j10"
+func FormatMoves(points []Point, size int) string {
+	var sb strings.Builder
+	for _, p := range points {
+		sb.WriteString(p.Notation(size))
+	}
+	return sb.String()
+}
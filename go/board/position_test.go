@@ -0,0 +1,79 @@
+package board
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMoves(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []Point
+		ok   bool
+	}{
+		{"", nil, true},
+		{"h8i9j10", []Point{{X: 7, Y: 7}, {X: 8, Y: 6}, {X: 9, Y: 5}}, true},
+		{"a1o15", []Point{{X: 0, Y: 14}, {X: 14, Y: 0}}, true},
+		{"H8, I9 j10", []Point{{X: 7, Y: 7}, {X: 8, Y: 6}, {X: 9, Y: 5}}, true},
+		{"h8p1", nil, false},
+		{"h8i", nil, false},
+		{"h8;i9", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoves(tt.s, 15)
+		if (err == nil) != tt.ok || tt.ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseMoves(%q) = %v, %v; want %v, ok %t", tt.s, got, err, tt.want, tt.ok)
+		}
+		if tt.ok && FormatMoves(got, 15) != FormatMoves(tt.want, 15) {
+			t.Errorf("FormatMoves(ParseMoves(%q)) = %q", tt.s, FormatMoves(got, 15))
+		}
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	tests := []struct {
+		s    string
+		want *Position
+	}{
+		{"15;renju;w;h8j10;i9", &Position{Size: 15, Rule: Renju, Turn: White,
+			Black: []Point{{X: 7, Y: 7}, {X: 9, Y: 5}}, White: []Point{{X: 8, Y: 6}}}},
+		{"15;freestyle;b;;", &Position{Size: 15, Rule: Freestyle, Turn: Black}},
+		{"19;standard;b;a1s19;", &Position{Size: 19, Rule: Standard, Turn: Black,
+			Black: []Point{{X: 0, Y: 18}, {X: 18, Y: 0}}}},
+		{"15;renju;w;h8", nil},
+		{"0;renju;b;;", nil},
+		{"27;renju;b;;", nil},
+		{"15;caro;b;;", nil},
+		{"15;renju;x;;", nil},
+		{"15;renju;b;h8;h8", nil},
+		{"15;renju;b;h8h8;", nil},
+		{"15;renju;b;p1;", nil},
+	}
+	for _, tt := range tests {
+		got, err := ParsePosition(tt.s)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("ParsePosition(%q) = %v, want an error", tt.s, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePosition(%q) = %+v, %v; want %+v", tt.s, got, err, tt.want)
+			continue
+		}
+		if got.String() != tt.s {
+			t.Errorf("ParsePosition(%q).String() = %q", tt.s, got.String())
+		}
+	}
+}
+
+func TestPositionOf(t *testing.T) {
+	pos, err := ParsePosition("15;renju;w;h8j10;i9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 从棋盘收集棋子时按行从上到下排列，同样的局面总是得到同样的文字
+	if got := PositionOf(pos.Board(), pos.Rule, pos.Turn).String(); got != "15;renju;w;j10h8;i9" {
+		t.Errorf("PositionOf(Board()) = %q, want the stones sorted from the top", got)
+	}
+}
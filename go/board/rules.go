@@ -0,0 +1,193 @@
+package board
+
+import "fmt"
+
+// Rule 是胜负规则
+type Rule int
+
+const (
+	Freestyle Rule = iota // 五个或更多连成一线获胜
+	Standard              // 恰好五个连成一线才获胜
+	Renju                 // 连珠：黑棋恰好五个获胜，并且不能下长连、四四、三三；白棋五个或更多获胜
+)
+
+// RuleNames 是各规则的名字，下标就是Rule的值
+var RuleNames = []string{"freestyle", "standard", "renju"}
+
+func (r Rule) String() string {
+	if r < 0 || int(r) >= len(RuleNames) {
+		return fmt.Sprintf("rule(%d)", r)
+	}
+	return RuleNames[r]
+}
+
+// ParseRule 按名字解析规则
+func ParseRule(s string) (Rule, error) {
+	for i, name := range RuleNames {
+		if s == name {
+			return Rule(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown rule %q, want one of %v", s, RuleNames)
+}
+
+func (r Rule) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rule) UnmarshalText(text []byte) error {
+	rule, err := ParseRule(string(text))
+	if err != nil {
+		return err
+	}
+	*r = rule
+	return nil
+}
+
+// 包括p点在内，p点所在的dir方向上与p同色的连续棋子数
+func lineLength(board [][]Color, p Point, dir Direction) int {
+	color := board[p.Y][p.X]
+	count := 1
+	for _, sign := range []int{1, -1} {
+		for k := 1; ; k++ {
+			pk := p.Move(dir, sign*k)
+			if !pk.CheckRange() || board[pk.Y][pk.X] != color {
+				break
+			}
+			count++
+		}
+	}
+	return count
+}
+
+func (r Rule) exactFive(color Color) bool {
+	return r == Standard || r == Renju && color == Black
+}
+
+// AllowsOverline 返回color方连成六个或更多时是否也算获胜
+func (r Rule) AllowsOverline(color Color) bool {
+	return !r.exactFive(color)
+}
+
+// IsWin 返回刚在p点落子之后，p点的这一方是否获胜
+func (r Rule) IsWin(board [][]Color, p Point) bool {
+	color := board[p.Y][p.X]
+	for _, dir := range FourDirections {
+		n := lineLength(board, p, dir)
+		if n == 5 || n > 5 && !r.exactFive(color) {
+			return true
+		}
+	}
+	return false
+}
+
+// WinningLine 返回刚在p点落子之后连成五的那条线上的全部棋子，从一端排到另一端，没有获胜时返回nil
+func (r Rule) WinningLine(board [][]Color, p Point) []Point {
+	color := board[p.Y][p.X]
+	for _, dir := range FourDirections {
+		n := lineLength(board, p, dir)
+		if n != 5 && (n < 5 || r.exactFive(color)) {
+			continue
+		}
+		end := p
+		for q := p.Move(dir, 1); q.CheckRange() && board[q.Y][q.X] == color; q = q.Move(dir, 1) {
+			end = q
+		}
+		line := make([]Point, n)
+		for k := range line {
+			line[k] = end.Move(dir, -k)
+		}
+		return line
+	}
+	return nil
+}
+
+// IsForbidden 返回在空的p点落color方的子是否是禁手，只有连珠规则的黑棋有禁手
+func (r Rule) IsForbidden(board [][]Color, p Point, color Color) bool {
+	if r != Renju || color != Black || board[p.Y][p.X] != Empty {
+		return false
+	}
+	board[p.Y][p.X] = color
+	defer func() { board[p.Y][p.X] = Empty }()
+	if r.IsWin(board, p) {
+		return false
+	}
+	fours, threes := 0, 0
+	for _, dir := range FourDirections {
+		if lineLength(board, p, dir) > 5 {
+			return true
+		}
+		fivePoints := r.fivePointsInLine(board, p, dir, color)
+		switch {
+		case len(fivePoints) == 1:
+			fours++
+		case len(fivePoints) >= 2:
+			// 活四的两个成五点相距5格，算一个四；否则是同一条线上的两个四，例如1_111_1
+			if len(fivePoints) == 2 && fivePoints[0].Distance(fivePoints[1]) == 5 {
+				fours++
+			} else {
+				fours += 2
+			}
+		case r.isOpenThree(board, p, dir, color):
+			threes++
+		}
+	}
+	return fours >= 2 || threes >= 2
+}
+
+// p点所在的dir方向上，前后4格内能让color方恰好成五的空位
+func (r Rule) fivePointsInLine(board [][]Color, p Point, dir Direction, color Color) []Point {
+	var result []Point
+	for k := -4; k <= 4; k++ {
+		q := p.Move(dir, k)
+		if k == 0 || !q.CheckRange() || board[q.Y][q.X] != Empty {
+			continue
+		}
+		board[q.Y][q.X] = color
+		if lineLength(board, q, dir) == 5 {
+			result = append(result, q)
+		}
+		board[q.Y][q.X] = Empty
+	}
+	return result
+}
+
+// p点所在的dir方向上是否是活三，即再下一个子就能成活四。简化处理，不检查成活四的那一步本身是否是禁手
+func (r Rule) isOpenThree(board [][]Color, p Point, dir Direction, color Color) bool {
+	for k := -4; k <= 4; k++ {
+		q := p.Move(dir, k)
+		if k == 0 || !q.CheckRange() || board[q.Y][q.X] != Empty {
+			continue
+		}
+		board[q.Y][q.X] = color
+		fivePoints := r.fivePointsInLine(board, q, dir, color)
+		board[q.Y][q.X] = Empty
+		if len(fivePoints) == 2 && fivePoints[0].Distance(fivePoints[1]) == 5 {
+			return true
+		}
+	}
+	return false
+}
+
+// FivePossible 返回color方以后是否还有可能连成五，也就是棋盘上是否还有连续5格里没有对方的棋子。
+// 不考虑连珠规则中黑棋长连不算赢的情况，所以返回true不代表一定能赢
+func (r Rule) FivePossible(board [][]Color, color Color) bool {
+	for y := 0; y < Size; y++ {
+		for x := 0; x < Size; x++ {
+			p := Point{x, y}
+		next:
+			for _, dir := range FourDirections {
+				if !p.Move(dir, 4).CheckRange() {
+					continue
+				}
+				for k := 0; k < 5; k++ {
+					if q := p.Move(dir, k); board[q.Y][q.X] == color.Conversion() {
+						continue next
+					}
+				}
+				return true
+			}
+		}
+	}
+	return false
+}
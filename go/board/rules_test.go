@@ -0,0 +1,39 @@
+package board
+
+import "testing"
+
+func TestIsForbiddenAtAnySize(t *testing.T) {
+	defer func(size int) { Size = size }(Size)
+	// 棋形都以右下角为准摆放，不同大小的棋盘上结果应该一样
+	tests := []struct {
+		name   string
+		stones func(n int) []Point // n是棋盘大小
+		p      func(n int) Point
+		want   bool
+	}{
+		{"double three", func(n int) []Point {
+			return []Point{{X: n - 4, Y: n - 3}, {X: n - 5, Y: n - 3}, {X: n - 3, Y: n - 4}, {X: n - 3, Y: n - 5}}
+		}, func(n int) Point { return Point{X: n - 3, Y: n - 3} }, true},
+		{"three against the edge", func(n int) []Point {
+			return []Point{{X: n - 2, Y: n - 3}, {X: n - 3, Y: n - 3}, {X: n - 1, Y: n - 4}, {X: n - 1, Y: n - 5}}
+		}, func(n int) Point { return Point{X: n - 1, Y: n - 3} }, false},
+		{"overline at the edge", func(n int) []Point {
+			return []Point{{X: n - 6, Y: n - 1}, {X: n - 5, Y: n - 1}, {X: n - 4, Y: n - 1}, {X: n - 2, Y: n - 1}, {X: n - 1, Y: n - 1}}
+		}, func(n int) Point { return Point{X: n - 3, Y: n - 1} }, true},
+	}
+	for _, n := range []int{15, 19} {
+		Size = n
+		for _, tt := range tests {
+			b := make([][]Color, n)
+			for i := range b {
+				b[i] = make([]Color, n)
+			}
+			for _, s := range tt.stones(n) {
+				b[s.Y][s.X] = Black
+			}
+			if got := Renju.IsForbidden(b, tt.p(n), Black); got != tt.want {
+				t.Errorf("size %d, %s: IsForbidden = %t, want %t", n, tt.name, got, tt.want)
+			}
+		}
+	}
+}
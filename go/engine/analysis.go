@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"strings"
+)
+
+// Line 是一条主要变例，Value是站在机器人一方的评分，PV从机器人的着法开始
+type Line struct {
+	Value int
+	PV    []board.Point
+}
+
+func (l Line) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "score %d:", l.Value)
+	for _, p := range l.PV {
+		sb.WriteString(" ")
+		sb.WriteString(p.String())
+	}
+	return sb.String()
+}
+
+// Analysis 是一次搜索的结果，Lines按评分从高到低排列，第一条就是要下的着法
+type Analysis struct {
+	Depth  int
+	Reason string // 不经过搜索直接得出结果的原因，为空表示是搜索的结果
+	Lines  []Line
+	Stats  SearchStats
+}
+
+func newForcedAnalysis(reason string, p board.Point, depth, value int) *Analysis {
+	return &Analysis{Depth: depth, Reason: reason, Lines: []Line{{value, []board.Point{p}}}}
+}
+
+func (a *Analysis) String() string {
+	var sb strings.Builder
+	if a.Reason != "" {
+		fmt.Fprintf(&sb, "%s, depth %d\n", a.Reason, a.Depth)
+	} else {
+		fmt.Fprintf(&sb, "depth %d\n", a.Depth)
+	}
+	for i, line := range a.Lines {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, line)
+	}
+	return sb.String()
+}
+
+// Analyzer 是可以提供最近一次分析结果的玩家
+type Analyzer interface {
+	LastAnalysis() *Analysis
+}
@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"log"
+	"math/rand"
+)
+
+type boardStatus struct {
+	blackHash [][]uint64
+	whiteHash [][]uint64
+	board     [][]board.Color
+	hashes    [symmetryCount]uint64 // 8种对称变换下的局面哈希
+	count     int
+}
+
+func (b *boardStatus) initBoardStatus() {
+	b.blackHash = make([][]uint64, board.Size)
+	b.whiteHash = make([][]uint64, board.Size)
+	b.board = make([][]board.Color, board.Size)
+	r := rand.New(rand.NewSource(1551980916123)) //rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < board.Size; i++ {
+		b.blackHash[i] = make([]uint64, board.Size)
+		b.whiteHash[i] = make([]uint64, board.Size)
+		b.board[i] = make([]board.Color, board.Size)
+		for j := 0; j < board.Size; j++ {
+			b.blackHash[i][j] = r.Uint64()
+			b.whiteHash[i][j] = r.Uint64()
+		}
+	}
+}
+
+func (b *boardStatus) setIfEmpty(p board.Point, color board.Color) bool {
+	if b.board[p.Y][p.X] != board.Empty {
+		return false
+	}
+	switch color {
+	case board.Empty:
+		return true
+	case board.Black, board.White:
+		b.toggleHash(p, color)
+	default:
+		log.Printf("illegal argument: %s%s\n", p, color)
+		return false
+	}
+	b.board[p.Y][p.X] = color
+	b.count++
+	return true
+}
+
+func (b *boardStatus) set(p board.Point, color board.Color) {
+	if b.board[p.Y][p.X] == color {
+		return
+	}
+	switch color {
+	case board.Empty:
+	case board.Black, board.White:
+		b.toggleHash(p, color)
+		b.count++
+	default:
+		log.Printf("illegal argument: %s%s\n", p, color)
+		return
+	}
+	if old := b.board[p.Y][p.X]; old != board.Empty {
+		b.toggleHash(p, old)
+		b.count--
+	}
+	b.board[p.Y][p.X] = color
+}
+
+// 清空棋盘，哈希也随之清零
+func (b *boardStatus) clear() {
+	for y := range b.board {
+		for x := range b.board[y] {
+			b.set(board.Point{X: x, Y: y}, board.Empty)
+		}
+	}
+}
+
+func (b *boardStatus) toggleHash(p board.Point, color board.Color) {
+	table := b.blackHash
+	if color == board.White {
+		table = b.whiteHash
+	}
+	for s := symmetry(0); s < symmetryCount; s++ {
+		q := s.apply(p)
+		b.hashes[s] ^= table[q.Y][q.X]
+	}
+}
+
+func (b *boardStatus) get(p board.Point) board.Color {
+	return b.board[p.Y][p.X]
+}
+
+func (b *boardStatus) isNeighbor(p board.Point) bool {
+	if !p.CheckRange() {
+		return false
+	}
+	for i := -2; i <= 2; i++ {
+		for j := -2; j <= 2; j++ {
+			p2 := board.Point{X: p.X + j, Y: p.Y + i}
+			if p2.CheckRange() && b.get(p2) > board.Empty {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 置换表，键是对称归一化之后的局面哈希，着法也按归一化的方向保存
+type boardCache map[uint64]map[int]*pointAndValue
+
+func (c boardCache) putIntoCache(b *boardStatus, deep int, val *pointAndValue) {
+	key, s := b.canonicalHash()
+	m, ok := c[key]
+	if !ok {
+		m = make(map[int]*pointAndValue)
+		c[key] = m
+	}
+	m[deep] = &pointAndValue{s.apply(val.p), val.Value}
+}
+
+func (c boardCache) getFromCache(b *boardStatus, deep int) *pointAndValue {
+	key, s := b.canonicalHash()
+	v, ok := c[key][deep]
+	if !ok {
+		return nil
+	}
+	return &pointAndValue{s.inverse().apply(v.p), v.Value}
+}
+
+func (c boardCache) getBestMove(b *boardStatus) (board.Point, bool) {
+	key, s := b.canonicalHash()
+	deep := 0
+	var p board.Point
+	for d, v := range c[key] {
+		if d > deep && v != nil {
+			deep, p = d, v.p
+		}
+	}
+	return s.inverse().apply(p), deep > 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+)
+
+type bookMove struct {
+	p      board.Point // 对称变换后的坐标
+	weight int
+}
+
+// OpeningBook 是开局库，键是对称归一化之后的局面哈希
+type OpeningBook map[uint64][]bookMove
+
+func (b OpeningBook) add(hash uint64, p board.Point, weight int) {
+	for i := range b[hash] {
+		if b[hash][i].p == p {
+			b[hash][i].weight += weight
+			return
+		}
+	}
+	b[hash] = append(b[hash], bookMove{p, weight})
+}
+
+// 在开局库中按权重随机选一个着法，返回的坐标已经变换回原棋盘的方向
+func (b OpeningBook) probe(bs *boardStatus, rnd *rand.Rand) (board.Point, bool) {
+	hash, s := bs.canonicalHash()
+	moves := b[hash]
+	total := 0
+	for _, m := range moves {
+		total += m.weight
+	}
+	if total <= 0 {
+		return board.Point{}, false
+	}
+	n := rnd.Intn(total)
+	for _, m := range moves {
+		n -= m.weight
+		if n < 0 {
+			p := s.inverse().apply(m.p)
+			if !p.CheckRange() || bs.get(p) != board.Empty {
+				return board.Point{}, false
+			}
+			return p, true
+		}
+	}
+	return board.Point{}, false
+}
+
+// AddGame 把一局棋的前plies步加入开局库，onlyColor不为board.Empty时只加入这一方的着法
+func (b OpeningBook) AddGame(moves []board.Point, plies, weight int, onlyColor board.Color) {
+	var bs boardStatus
+	bs.initBoardStatus()
+	color := board.Black
+	for i, p := range moves {
+		if i >= plies || !p.CheckRange() || bs.get(p) != board.Empty {
+			return
+		}
+		if onlyColor == board.Empty || onlyColor == color {
+			hash, s := bs.canonicalHash()
+			b.add(hash, s.apply(p), weight)
+		}
+		bs.set(p, color)
+		color = color.Conversion()
+	}
+}
+
+// LoadOpeningBook 读取开局库文件。文件格式：第一行是"size 15"，之后每行是"哈希 h8 权重"，坐标也可以写作"x,y"
+func LoadOpeningBook(name string) (OpeningBook, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	book := make(OpeningBook)
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var size int
+		if _, err := fmt.Sscanf(text, "size %d", &size); err == nil {
+			if size != board.Size {
+				return nil, fmt.Errorf("%s: book is for board size %d, current size is %d", name, size, board.Size)
+			}
+			continue
+		}
+		var hash uint64
+		var point string
+		var weight int
+		if _, err := fmt.Sscanf(text, "%x %s %d", &hash, &point, &weight); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		var p board.Point
+		if strings.Contains(point, ",") { // 以前的开局库写作"x,y"
+			err = p.UnmarshalText([]byte(point))
+		} else {
+			p, err = board.ParseNotation(point, board.Size)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		book.add(hash, p, weight)
+	}
+	return book, scanner.Err()
+}
+
+// Save 按LoadOpeningBook的格式写入文件
+func (b OpeningBook) Save(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "size %d\n", board.Size)
+	hashes := make([]uint64, 0, len(b))
+	for hash := range b {
+		hashes = append(hashes, hash)
+	}
+	slices.Sort(hashes)
+	for _, hash := range hashes {
+		for _, m := range b[hash] {
+			fmt.Fprintf(w, "%016x %s %d\n", hash, m.p, m.weight)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
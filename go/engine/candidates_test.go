@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// 在r的棋盘上摆上black和white两方的棋子，格式和board.ParseMoves一样
+func setStones(t *testing.T, r *Robot, black, white string) {
+	t.Helper()
+	for color, s := range map[board.Color]string{board.Black: black, board.White: white} {
+		points, err := board.ParseMoves(s, board.Size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range points {
+			r.set(p, color)
+		}
+	}
+}
+
+func notations(points []board.Point) []string {
+	var s []string
+	for _, p := range points {
+		s = append(s, p.String())
+	}
+	slices.Sort(s)
+	return s
+}
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		name         string
+		black, white string
+		width        int
+		want         []string // 为nil时只检查个数等于width
+		oneOf        bool     // 只返回want中的一个
+	}{
+		{"own five", "h8i8j8k8", "a1c1e1", 12, []string{"g8", "l8"}, true},
+		{"block the only five point", "g8a1c1", "h8i8j8k8", 12, []string{"l8"}, false},
+		{"all defenses against an open three survive a narrow width", "c3d3e3", "h8i8j8", 1,
+			[]string{"a3", "b3", "f3", "g3", "g8", "k8"}, false},
+		{"quiet position uses the width", "h8", "i9", 5, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRobot(t, board.Black, Normal, board.Freestyle, "")
+			setStones(t, r, tt.black, tt.white)
+			r.maxCountEachLevel = tt.width
+			queue := r.candidates(board.Black, 2)
+			var got []board.Point
+			for _, obj := range queue {
+				got = append(got, obj.p)
+			}
+			switch {
+			case tt.oneOf:
+				if len(got) != 1 || !slices.Contains(tt.want, got[0].String()) {
+					t.Errorf("candidates = %v, want one of %v", got, tt.want)
+				}
+			case tt.want == nil:
+				if len(got) != tt.width {
+					t.Errorf("candidates = %v, want %d of them", got, tt.width)
+				}
+			case !reflect.DeepEqual(notations(got), tt.want):
+				t.Errorf("candidates = %v, want %v", notations(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateKill(t *testing.T) {
+	// 例如黑k9冲四逼白j9，再下k8成双四。只冲一次四赢不了
+	r := newTestRobot(t, board.Black, Normal, board.Freestyle, "")
+	setStones(t, r, "h8i8j8k10k11l9m9n9", "g8k12o9a1")
+	if p, ok := r.calculateKill(board.Black, true, 2); ok {
+		t.Errorf("calculateKill with 2 plies found %s", p)
+	}
+	if p, ok := r.calculateKill(board.White, true, 4); ok {
+		t.Errorf("calculateKill for white found %s", p)
+	}
+	p, ok := r.calculateKill(board.Black, true, 4)
+	if !ok {
+		t.Fatal("calculateKill with 4 plies found nothing")
+	}
+	// 冲四之后白方只能挡在成五点上，挡住之后黑方还能再冲出双四
+	r.set(p, board.Black)
+	block, ok := r.findForm5(board.Black)
+	if !ok {
+		t.Fatalf("%s is not a four", p)
+	}
+	r.set(block, board.White)
+	next, ok := r.calculateKill(board.Black, true, 2)
+	if !ok {
+		t.Fatalf("no double four after %s %s", p, block)
+	}
+	if n := r.countForm5After(next, board.Black); n < 2 {
+		t.Errorf("%s after %s %s leaves %d five points, want 2", next, p, block, n)
+	}
+	r.set(block, board.Empty)
+	r.set(p, board.Empty)
+	a, err := r.Analyze(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Reason != "kill" || a.Depth != 4 || a.Lines[0].PV[0] != p {
+		t.Errorf("Analyze = %s", a)
+	}
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"strconv"
+	"strings"
+)
+
+// Config 是引擎配置，写作"level=normal,depth=4,width=12,kill=8,blunder=0,weights=weights.json"，
+// 没写的项使用Level的默认值
+type Config struct {
+	Level   Difficulty
+	Depth   int
+	Width   int
+	Kill    int
+	Blunder float64
+	Weights *EvalWeights
+}
+
+// ParseConfig 解析"key=value,..."格式的引擎配置
+func ParseConfig(s string) (Config, error) {
+	c := Config{Level: Hard, Depth: -1, Width: -1, Kill: -1, Blunder: -1}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return c, fmt.Errorf("invalid engine option %q, want key=value", item)
+		}
+		var err error
+		switch key {
+		case "level":
+			c.Level, err = ParseDifficulty(value)
+		case "depth":
+			c.Depth, err = parsePositive(value)
+		case "width":
+			c.Width, err = parsePositive(value)
+		case "kill":
+			c.Kill, err = strconv.Atoi(value)
+		case "blunder":
+			c.Blunder, err = strconv.ParseFloat(value, 64)
+		case "weights":
+			c.Weights, err = LoadEvalWeights(value)
+		default:
+			err = fmt.Errorf("unknown engine option %q", key)
+		}
+		if err != nil {
+			return c, fmt.Errorf("engine option %q: %w", item, err)
+		}
+	}
+	return c, nil
+}
+
+func parsePositive(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err == nil && n <= 0 {
+		err = fmt.Errorf("%d is not positive", n)
+	}
+	return n, err
+}
+
+// NewPlayer 按配置创建执color的机器人
+func (c Config) NewPlayer(color board.Color) *Robot {
+	rp := NewRobot(color, c.Level)
+	if c.Depth > 0 {
+		rp.maxLevelCount = c.Depth
+	}
+	if c.Width > 0 {
+		rp.maxCountEachLevel = c.Width
+	}
+	if c.Kill >= 0 {
+		rp.maxCheckmateCount = c.Kill
+	}
+	if c.Blunder >= 0 {
+		rp.blunderRate = c.Blunder
+	}
+	if c.Weights != nil {
+		rp.weights = c.Weights
+	}
+	return rp
+}
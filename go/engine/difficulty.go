@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"math/rand"
+)
+
+// Difficulty 是机器人的难度
+type Difficulty int32
+
+const (
+	Beginner Difficulty = iota
+	Easy
+	Normal
+	Hard
+)
+
+// DifficultyNames 是各难度的名字，下标就是Difficulty的值
+var DifficultyNames = []string{"beginner", "easy", "normal", "hard"}
+
+func (d Difficulty) String() string {
+	if d < 0 || int(d) >= len(DifficultyNames) {
+		return fmt.Sprintf("difficulty(%d)", d)
+	}
+	return DifficultyNames[d]
+}
+
+// ParseDifficulty 按名字解析难度
+func ParseDifficulty(s string) (Difficulty, error) {
+	for i, name := range DifficultyNames {
+		if s == name {
+			return Difficulty(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown difficulty %q, want one of %v", s, DifficultyNames)
+}
+
+type difficultyParams struct {
+	maxLevelCount     int
+	maxCountEachLevel int
+	maxCheckmateCount int
+	blunderRate       float64 // 不走最佳着法，而是按权重随机选一个候选着法的概率
+	blunderWidth      int     // 随机选择时考虑的候选着法个数
+}
+
+var difficultyTable = []difficultyParams{
+	Beginner: {2, 6, 0, 0.5, 8},
+	Easy:     {2, 8, 4, 0.25, 5},
+	Normal:   {4, 12, 8, 0.1, 3},
+	Hard:     {6, 16, 12, 0, 0},
+}
+
+func (r *Robot) applyDifficulty(d Difficulty) {
+	params := difficultyTable[d]
+	r.maxLevelCount = params.maxLevelCount
+	r.maxCountEachLevel = params.maxCountEachLevel
+	r.maxCheckmateCount = params.maxCheckmateCount
+	r.blunderRate = params.blunderRate
+	r.blunderWidth = params.blunderWidth
+}
+
+// SetDifficulty 修改难度，可以在其它goroutine中调用，在下一次Play时生效
+func (r *Robot) SetDifficulty(d Difficulty) {
+	r.pendingDifficulty.Store(int32(d))
+}
+
+// 按照静态评分的权重，从前blunderWidth个候选着法中随机选一个
+func (r *Robot) blunder(rnd *rand.Rand) (board.Point, bool) {
+	queue := r.candidates(r.pColor, 1)
+	if len(queue) > r.blunderWidth {
+		queue = queue[:r.blunderWidth]
+	}
+	total := 0
+	for _, obj := range queue {
+		total += obj.Value + 1
+	}
+	if total <= 0 {
+		return board.Point{}, false
+	}
+	n := rnd.Intn(total)
+	for _, obj := range queue {
+		n -= obj.Value + 1
+		if n < 0 {
+			return obj.p, true
+		}
+	}
+	return board.Point{}, false
+}
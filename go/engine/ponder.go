@@ -0,0 +1,47 @@
+package engine
+
+import "github.com/CuteReimu/gobang/board"
+
+// 在对方思考时，猜测对方的应手并提前搜索，搜索结果留在置换表里，对方真的这么下时就能直接命中
+func (r *Robot) startPondering(a *Analysis, played board.Point) {
+	if guess, ok := r.predictReply(a, played); ok {
+		r.ponder(guess)
+	}
+}
+
+// 假设对方下在guess，在后台搜索之后的局面
+func (r *Robot) ponder(guess board.Point) {
+	done := make(chan struct{})
+	r.ponderDone = done
+	r.ponderGuess = guess
+	r.stopSearch.Store(false)
+	go func() {
+		defer close(done)
+		r.set(guess, r.pColor.Conversion())
+		r.max(r.maxLevelCount, 100000000)
+		r.set(guess, board.Empty)
+	}()
+}
+
+// 停止后台思考并等待其退出，返回之前猜测的对方应手
+func (r *Robot) stopPondering() (board.Point, bool) {
+	if r.ponderDone == nil {
+		return board.Point{}, false
+	}
+	r.stopSearch.Store(true)
+	<-r.ponderDone
+	r.ponderDone = nil
+	r.stopSearch.Store(false)
+	return r.ponderGuess, true
+}
+
+// 优先用主要变例中的下一步，没有的话取对方静态评分最高的着法
+func (r *Robot) predictReply(a *Analysis, played board.Point) (board.Point, bool) {
+	if pv := a.Lines[0].PV; len(pv) > 1 && pv[0] == played && r.get(pv[1]) == board.Empty {
+		return pv[1], true
+	}
+	if queue := r.candidates(r.pColor.Conversion(), 1); len(queue) > 0 {
+		return queue[0].p, true
+	}
+	return board.Point{}, false
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/game"
+	"testing"
+)
+
+func TestPonder(t *testing.T) {
+	const moves = "h8i9h9"
+	tests := []struct {
+		name     string
+		hitGuess bool // 白方是否正好下在猜测的点上
+	}{
+		{"guess played", true},
+		{"guess missed", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRobot(t, board.White, Normal, board.Freestyle, moves)
+			r.maxCheckmateCount = 0
+			r.Ponder = true
+			p, err := r.Play()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Notify(game.MovePlayed{Color: board.White, P: p, Number: 4})
+			if r.ponderDone == nil {
+				t.Fatal("no pondering after the robot's own move")
+			}
+			<-r.ponderDone // 让后台搜索完整地搜完
+			reply := r.ponderGuess
+			if !tt.hitGuess {
+				queue := r.candidates(board.Black, 1)
+				for _, obj := range queue {
+					if obj.p != reply {
+						reply = obj.p
+						break
+					}
+				}
+			}
+			r.Notify(game.MovePlayed{Color: board.Black, P: reply, Number: 5})
+			if r.ponderDone != nil || r.stopSearch.Load() {
+				t.Fatal("pondering did not stop on the opponent's move")
+			}
+
+			// 棋盘和哈希要和直接摆出同样局面的机器人一致
+			fresh := newTestRobot(t, board.White, Normal, board.Freestyle, moves+p.String()+reply.String())
+			fresh.maxCheckmateCount = 0
+			if r.hashes != fresh.hashes || r.count != fresh.count {
+				t.Fatalf("board after pondering differs from a fresh one")
+			}
+			if _, err := r.Play(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := fresh.Play(); err != nil {
+				t.Fatal(err)
+			}
+			pondered, cold := r.LastAnalysis().Stats, fresh.LastAnalysis().Stats
+			if tt.hitGuess && (pondered.CacheHits == 0 || pondered.Nodes >= cold.Nodes) {
+				t.Errorf("after a correct guess: %d nodes, %d cache hits; a cold search took %d nodes", pondered.Nodes, pondered.CacheHits, cold.Nodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,859 @@
+// Package engine 是五子棋的搜索引擎：博弈树搜索、算杀、开局库、估值权重和难度设置。
+package engine
+
+import (
+	"errors"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/game"
+	"log/slog"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Robot 是用博弈树搜索下棋的机器人，实现了game.Player
+type Robot struct {
+	boardStatus
+	boardCache
+	pColor            board.Color
+	maxLevelCount     int
+	maxCountEachLevel int
+	maxCheckmateCount int
+	killers           [][]board.Point
+	history           []int
+	stats             SearchStats
+	MultiPV           int // 每次Play分析的变例数
+	analysis          *Analysis
+	blunderRate       float64
+	blunderWidth      int
+	pendingDifficulty atomic.Int32
+	rand              *rand.Rand
+	Book              OpeningBook // 为nil时不使用开局库
+	weights           *EvalWeights
+	Ponder            bool // 是否在对方思考时后台搜索
+	Resign            bool // 是否在搜索结果必败时认输
+	stopSearch        atomic.Bool
+	ponderDone        chan struct{}
+	ponderGuess       board.Point
+	moveTime          time.Duration // 每步的思考时间，0表示不限时，只搜索固定的深度
+	rule              board.Rule    // 由GameStarted得知，用来避开禁手
+}
+
+// NewRobot 创建执color、难度为level的机器人
+func NewRobot(color board.Color, level Difficulty) *Robot {
+	rp := &Robot{
+		boardCache: make(boardCache),
+		pColor:     color,
+		MultiPV:    1,
+		history:    make([]int, board.Size*board.Size),
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		weights:    DefaultEvalWeights(),
+	}
+	rp.applyDifficulty(level)
+	rp.pendingDifficulty.Store(-1)
+	rp.initBoardStatus()
+	return rp
+}
+
+func (r *Robot) Color() board.Color {
+	return r.pColor
+}
+
+func (r *Robot) Play() (board.Point, error) {
+	r.stopPondering()
+	r.stopSearch.Store(false) // 上一步返回之后才到的Interrupt不算数
+	if d := r.pendingDifficulty.Swap(-1); d >= 0 {
+		r.applyDifficulty(Difficulty(d))
+	}
+	a, err := r.Analyze(r.MultiPV)
+	if err != nil {
+		return board.Point{}, err
+	}
+	r.analysis = a
+	if r.Resign && a.Lines[0].Value <= -r.winValue() {
+		return board.Point{}, game.ErrResign
+	}
+	p := a.Lines[0].PV[0]
+	if a.Reason == "" && r.rand.Float64() < r.blunderRate {
+		if p1, ok := r.blunder(r.rand); ok {
+			p = p1
+		}
+	}
+	return p, nil
+}
+
+// 有一方成五的局面估值超过这个值，其余棋形加起来远到不了。对方成五的局面估值在它的相反数以下，搜索到这里说明怎么下都会输
+func (r *Robot) winValue() int {
+	return r.weights.BoardFive * 4 / 5
+}
+
+// AcceptDraw 在自己已经不可能连成五，或者棋盘快下满而自己并不占优时同意和棋
+func (r *Robot) AcceptDraw() bool {
+	guess, pondering := r.stopPondering() // 后台思考会改动r.board，先停下来，拒绝之后接着想
+	nearlyFull := r.count >= board.Size*board.Size*9/10
+	accept := !r.rule.FivePossible(r.board, r.pColor) || nearlyFull && (r.analysis == nil || r.analysis.Lines[0].Value <= 0)
+	if !accept && pondering {
+		r.ponder(guess)
+	}
+	return accept
+}
+
+// LastAnalysis 返回最近一次Play的分析结果
+func (r *Robot) LastAnalysis() *Analysis {
+	return r.analysis
+}
+
+// Analyze 分析当前局面，返回最多n条主要变例，不改变棋盘
+func (r *Robot) Analyze(n int) (*Analysis, error) {
+	r.stats = SearchStats{}
+	position := board.PositionOf(r.board, r.rule, r.pColor)
+	start := time.Now()
+	a, err := r.search(n)
+	r.stats.Elapsed = time.Since(start)
+	if err != nil {
+		r.stats.log(slog.LevelError, err.Error(), r.pColor, position)
+		return nil, err
+	}
+	r.stats.Depth = a.Depth
+	a.Stats = r.stats
+	r.stats.log(slog.LevelInfo, "search", r.pColor, position)
+	return a, nil
+}
+
+func (r *Robot) search(n int) (*Analysis, error) {
+	if p, ok := r.Book.probe(&r.boardStatus, r.rand); ok && !r.forbidden(p, r.pColor) {
+		return newForcedAnalysis("book", p, 0, 0), nil
+	}
+	if r.count == 0 {
+		return newForcedAnalysis("opening", board.Point{X: board.Size / 2, Y: board.Size / 2}, 0, 0), nil
+	}
+	if p, ok := r.findForm5(r.pColor); ok && !r.forbidden(p, r.pColor) {
+		return newForcedAnalysis("five", p, 0, r.weights.BoardFive), nil
+	}
+	if p, ok := r.stop4(r.pColor); ok && !r.forbidden(p, r.pColor) {
+		// 挡住之后对方还有别的成五点，说明已经输了
+		r.set(p, r.pColor)
+		_, lost := r.findForm5(r.pColor.Conversion())
+		r.set(p, board.Empty)
+		if lost {
+			return newForcedAnalysis("block four", p, 0, -r.weights.BoardFive), nil
+		}
+		return newForcedAnalysis("block four", p, 0, 0), nil
+	}
+	for i := 2; i <= r.maxCheckmateCount; i += 2 {
+		if p, ok := r.calculateKill(r.pColor, true, i); ok && !r.forbidden(p, r.pColor) {
+			return newForcedAnalysis("kill", p, i, r.weights.BoardFive), nil
+		}
+	}
+	for i := range r.history {
+		r.history[i] /= 2
+	}
+	result, depth := r.deepen()
+	if result == nil {
+		// 棋盘快下满时搜索可能找不到结果，随便下一个空位
+		if queue := r.candidates(r.pColor, 1); len(queue) > 0 {
+			return newForcedAnalysis("fallback", queue[0].p, 0, 0), nil
+		}
+		return nil, errors.New("algorithm error")
+	}
+	a := &Analysis{Depth: depth}
+	a.Lines = append(a.Lines, Line{result.Value, r.principalVariation(result.p, depth)})
+	var others []Line
+	for _, obj := range r.candidates(r.pColor, depth) {
+		if len(others) >= n-1 {
+			break
+		}
+		if obj.p == result.p {
+			continue
+		}
+		r.set(obj.p, r.pColor)
+		v := r.min(depth-1, -100000000)
+		r.set(obj.p, board.Empty)
+		if v != nil {
+			others = append(others, Line{v.Value, r.principalVariation(obj.p, depth)})
+		}
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		return others[i].Value > others[j].Value
+	})
+	a.Lines = append(a.Lines, others...)
+	return a, nil
+}
+
+// 从first开始，沿着置换表中记录的最佳着法取出主要变例
+func (r *Robot) principalVariation(first board.Point, step int) []board.Point {
+	pv := []board.Point{first}
+	color := r.pColor
+	r.set(first, color)
+	for step--; step > 0; step-- {
+		color = color.Conversion()
+		v := r.getFromCache(&r.boardStatus, step)
+		if v == nil || r.get(v.p) != board.Empty {
+			break
+		}
+		pv = append(pv, v.p)
+		r.set(v.p, color)
+	}
+	for _, p := range pv {
+		r.set(p, board.Empty)
+	}
+	return pv
+}
+
+func (r *Robot) calculateKill(color board.Color, aggressive bool, step int) (board.Point, bool) {
+	r.stats.KillAttempts++
+	p := board.Point{}
+	if step <= 0 {
+		return p, false
+	}
+	for i := 0; i < board.Size; i++ {
+		for j := 0; j < board.Size; j++ {
+			p.X, p.Y = j, i
+			if r.get(p) == 0 {
+				r.set(p, color)
+				if !r.exists4(color.Conversion()) && (!aggressive || r.exists4(color)) {
+					if _, ok := r.calculateKill(color.Conversion(), !aggressive, step-1); !ok {
+						r.set(p, 0)
+						return p, true
+					}
+				}
+				r.set(p, 0)
+			}
+		}
+	}
+	return p, false
+}
+
+// 对方的成五点，也就是color方必须挡住的点
+func (r *Robot) stop4(color board.Color) (board.Point, bool) {
+	return r.findForm5(color.Conversion())
+}
+
+// color方是否有成五点，也就是有没有四
+func (r *Robot) exists4(color board.Color) bool {
+	_, ok := r.findForm5(color)
+	return ok
+}
+
+// color方的成五点，按规则不算获胜的长连不算
+func (r *Robot) findForm5(color board.Color) (board.Point, bool) {
+	p := board.Point{}
+	for i := 0; i < board.Size; i++ {
+		for j := 0; j < board.Size; j++ {
+			p.X, p.Y = j, i
+			if r.checkForm5ByPoint(p, color) {
+				return p, true
+			}
+		}
+	}
+	return p, false
+}
+
+// 在空的p点落color方的子能否按规则获胜，直接在r.board上判断，不影响哈希
+func (r *Robot) checkForm5ByPoint(p board.Point, color board.Color) bool {
+	if r.get(p) != board.Empty || !r.hasFourInWindow(p, color) {
+		return false
+	}
+	r.board[p.Y][p.X] = color
+	defer func() { r.board[p.Y][p.X] = board.Empty }()
+	return r.rule.IsWin(r.board, p)
+}
+
+// p点的某条线上，前后4格内是否至少有4个color方的子，用于快速排除不可能成五的点
+func (r *Robot) hasFourInWindow(p board.Point, color board.Color) bool {
+	for _, dir := range board.FourDirections {
+		count := 0
+		for k := -4; k <= 4; k++ {
+			if pk := p.Move(dir, k); k != 0 && pk.CheckRange() && r.get(pk) == color {
+				count++
+			}
+		}
+		if count >= 4 {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify 让机器人的棋盘跟着对局走，自己的着法也是收到通知之后才落到棋盘上，然后开始后台思考
+func (r *Robot) Notify(e game.Event) {
+	switch e := e.(type) {
+	case game.GameStarted:
+		r.stopPondering()
+		r.rule = e.Rule
+		r.clear()
+	case game.MovePlayed:
+		if guess, ok := r.stopPondering(); ok && e.Color != r.pColor {
+			Log.Info("ponder", "guess", guess.String(), "actual", e.P.String(), "hit", guess == e.P)
+		}
+		r.set(e.P, e.Color)
+		if e.Color == r.pColor && r.Ponder && r.analysis != nil {
+			r.startPondering(r.analysis, e.P)
+		}
+	case game.MoveUndone:
+		r.stopPondering()
+		r.set(e.P, board.Empty)
+	case game.GameOver:
+		r.stopPondering()
+	}
+}
+
+func (r *Robot) max(step int, foundminVal int) *pointAndValue {
+	if r.stopSearch.Load() {
+		return nil
+	}
+	r.stats.Nodes++
+	if v := r.getFromCache(&r.boardStatus, step); v != nil {
+		r.stats.CacheHits++
+		return v
+	}
+	r.stats.CacheMisses++
+	queue := r.candidates(r.pColor, step)
+	p := board.Point{}
+	if step == 1 {
+		if len(queue) == 0 {
+			Log.Error("algorithm error", "reason", "no candidate", "step", step)
+			return nil
+		}
+		p = queue[0].p
+		r.setIfEmpty(p, r.pColor)
+		val := r.evaluateBoard(r.pColor) - r.evaluateBoard(r.pColor.Conversion())
+		r.set(p, board.Empty)
+		result := &pointAndValue{p, val}
+		r.putIntoCache(&r.boardStatus, step, result)
+		return result
+	}
+	maxPoint := board.Point{}
+	maxVal := -100000000
+	for _, obj := range queue {
+		p = obj.p
+		r.set(p, r.pColor)
+		boardVal := r.evaluateBoard(r.pColor) - r.evaluateBoard(r.pColor.Conversion())
+		if boardVal > r.winValue() {
+			r.set(p, 0)
+			result := &pointAndValue{p, boardVal}
+			r.putIntoCache(&r.boardStatus, step, result)
+			return result
+		}
+		next := r.min(step-1, maxVal) //最大值最小值法
+		if next == nil {
+			r.set(p, 0)
+			if r.stopSearch.Load() {
+				return nil
+			}
+			continue
+		}
+		evathis := next.Value
+		if evathis >= foundminVal {
+			r.set(p, 0)
+			r.recordCutoff(step, p)
+			result := &pointAndValue{p, evathis}
+			r.putIntoCache(&r.boardStatus, step, result)
+			return result
+		}
+		if evathis > maxVal || evathis == maxVal && p.NearMidThan(maxPoint) {
+			maxVal = evathis
+			maxPoint = p
+		}
+		r.set(p, 0)
+	}
+	if maxVal < -99999999 {
+		return nil
+	}
+	result := &pointAndValue{maxPoint, maxVal}
+	r.putIntoCache(&r.boardStatus, step, result)
+	return result
+}
+
+func (r *Robot) min(step int, foundmaxVal int) *pointAndValue {
+	if r.stopSearch.Load() {
+		return nil
+	}
+	r.stats.Nodes++
+	if v := r.getFromCache(&r.boardStatus, step); v != nil {
+		r.stats.CacheHits++
+		return v
+	}
+	r.stats.CacheMisses++
+	queue := r.candidates(r.pColor.Conversion(), step)
+	p := board.Point{}
+	if step == 1 {
+		if len(queue) == 0 {
+			Log.Error("algorithm error", "reason", "no candidate", "step", step)
+			return nil
+		}
+		p = queue[0].p
+		r.setIfEmpty(p, r.pColor.Conversion())
+		val := r.evaluateBoard(r.pColor) - r.evaluateBoard(r.pColor.Conversion())
+		r.set(p, 0)
+		result := &pointAndValue{p, val}
+		r.putIntoCache(&r.boardStatus, step, result)
+		return result
+	}
+	var minPoint board.Point
+	minVal := 100000000
+	for _, obj := range queue {
+		p = obj.p
+		r.set(p, r.pColor.Conversion())
+		boardVal := r.evaluateBoard(r.pColor) - r.evaluateBoard(r.pColor.Conversion())
+		if boardVal < -r.winValue() {
+			r.set(p, 0)
+			result := &pointAndValue{p, boardVal}
+			r.putIntoCache(&r.boardStatus, step, result)
+			return result
+		}
+		next := r.max(step-1, minVal) //最大值最小值法
+		if next == nil {
+			r.set(p, 0)
+			if r.stopSearch.Load() {
+				return nil
+			}
+			continue
+		}
+		evathis := next.Value
+		if evathis <= foundmaxVal {
+			r.set(p, 0)
+			r.recordCutoff(step, p)
+			result := &pointAndValue{p, evathis}
+			r.putIntoCache(&r.boardStatus, step, result)
+			return result
+		}
+		if evathis < minVal || evathis == minVal && p.NearMidThan(minPoint) {
+			minVal = evathis
+			minPoint = p
+		}
+		r.set(p, 0)
+	}
+	if minVal > 99999999 {
+		return nil
+	}
+	result := &pointAndValue{minPoint, minVal}
+	r.putIntoCache(&r.boardStatus, step, result)
+	return result
+}
+
+// 生成color方的候选着法。能成五时只走成五；对方有四时只返回全部防守点；对方有活三时返回全部防守点和己方冲四；
+// 否则返回排序后的前maxCountEachLevel个点
+func (r *Robot) candidates(color board.Color, step int) pointAndValueSlice {
+	var queue pointAndValueSlice
+	p := board.Point{}
+	for i := 0; i < board.Size; i++ {
+		for j := 0; j < board.Size; j++ {
+			p.X, p.Y = j, i
+			if r.get(p) == 0 && r.isNeighbor(p) && !r.forbidden(p, color) {
+				evathis := r.evaluatePoint(p, color)
+				queue = append(queue, &pointAndValue{p, evathis})
+			}
+		}
+	}
+	r.orderMoves(queue, step)
+	if wins := queue.filter(func(p board.Point) bool { return r.checkForm5ByPoint(p, color) }); len(wins) > 0 {
+		return wins[:1]
+	}
+	opponent := color.Conversion()
+	if blocks := queue.filter(func(p board.Point) bool { return r.checkForm5ByPoint(p, opponent) }); len(blocks) > 0 {
+		return blocks
+	}
+	var threats []board.Point
+	for _, obj := range queue {
+		if r.countForm5After(obj.p, opponent) >= 2 {
+			threats = append(threats, obj.p)
+		}
+	}
+	if len(threats) > 0 {
+		defenses := queue.filter(func(p board.Point) bool {
+			return r.isDefense(p, color, threats) || r.countForm5After(p, color) >= 1
+		})
+		if len(defenses) > 0 {
+			return defenses
+		}
+	}
+	if len(queue) > r.maxCountEachLevel {
+		queue = queue[:r.maxCountEachLevel]
+	}
+	return queue
+}
+
+// 在p点落color方的子是否是禁手，直接在r.board上判断，不影响哈希
+func (r *Robot) forbidden(p board.Point, color board.Color) bool {
+	return r.rule.IsForbidden(r.board, p, color)
+}
+
+// 在p点落color方的子之后，threats中是否已经没有任何一个点能让对方成活四或双四
+func (r *Robot) isDefense(p board.Point, color board.Color, threats []board.Point) bool {
+	r.set(p, color)
+	defer r.set(p, board.Empty)
+	for _, t := range threats {
+		if t != p && r.countForm5After(t, color.Conversion()) >= 2 {
+			return false
+		}
+	}
+	return true
+}
+
+// 在p点落color方的子之后，color方有几个成五点
+func (r *Robot) countForm5After(p board.Point, color board.Color) int {
+	if r.get(p) != board.Empty || !r.hasTwoInLine(p, color) {
+		return 0
+	}
+	r.set(p, color)
+	defer r.set(p, board.Empty)
+	found := make(map[board.Point]bool)
+	for _, dir := range board.FourDirections {
+		for k := -4; k <= 4; k++ {
+			if pk := p.Move(dir, k); k != 0 && pk.CheckRange() && !found[pk] && r.checkForm5ByPoint(pk, color) {
+				found[pk] = true
+			}
+		}
+	}
+	return len(found)
+}
+
+// p点的某条线上，前后3格内是否至少有2个color方的子，用于快速排除不可能成四的点
+func (r *Robot) hasTwoInLine(p board.Point, color board.Color) bool {
+	for _, dir := range board.FourDirections {
+		count := 0
+		for k := -3; k <= 3; k++ {
+			if pk := p.Move(dir, k); k != 0 && pk.CheckRange() && r.get(pk) == color {
+				count++
+			}
+		}
+		if count >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// 置换表中的最佳着法排在最前，其次是杀手着法，其余按静态评分加历史得分排序
+func (r *Robot) orderMoves(queue pointAndValueSlice, step int) {
+	if step > 1 {
+		best, hasBest := r.getBestMove(&r.boardStatus)
+		for _, obj := range queue {
+			if hasBest && obj.p == best {
+				obj.Value += 1 << 30
+			} else if r.isKiller(step, obj.p) {
+				obj.Value += 1 << 29
+			}
+			obj.Value += r.history[obj.p.Hash()]
+		}
+	}
+	sort.Sort(queue)
+}
+
+func (r *Robot) isKiller(step int, p board.Point) bool {
+	if step >= len(r.killers) {
+		return false
+	}
+	for _, k := range r.killers[step] {
+		if k == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Robot) recordCutoff(step int, p board.Point) {
+	for len(r.killers) <= step {
+		r.killers = append(r.killers, nil)
+	}
+	if !r.isKiller(step, p) {
+		r.killers[step] = append([]board.Point{p}, r.killers[step]...)
+		if len(r.killers[step]) > 2 {
+			r.killers[step] = r.killers[step][:2]
+		}
+	}
+	r.history[p.Hash()] += step * step
+}
+
+func (r *Robot) evaluatePoint(p board.Point, color board.Color) int {
+	return r.evaluatePoint2(p, color, board.Black) + r.evaluatePoint2(p, color, board.White)
+}
+
+func (r *Robot) evaluatePoint2(p board.Point, me board.Color, plyer board.Color) (value int) {
+	w := r.weights
+	numoftwo := 0
+	getLine := func(p board.Point, dir board.Direction, j int) board.Color {
+		p2 := p.Move(dir, j)
+		if p2.CheckRange() {
+			return r.get(p2)
+		}
+		return -1
+	}
+	for _, dir := range board.EightDirections { // 8个方向
+		// 活四 01111* *代表当前空位置 0代表其他空位置 下同
+		if getLine(p, dir, -1) == plyer && getLine(p, dir, -2) == plyer && getLine(p, dir, -3) == plyer && getLine(p, dir, -4) == plyer && getLine(p, dir, -5) == 0 {
+			value += w.PointLiveFour
+			if me != plyer {
+				value -= w.PointFourOpponent
+			}
+			continue
+		}
+		// 死四A 21111*
+		if getLine(p, dir, -1) == plyer && getLine(p, dir, -2) == plyer && getLine(p, dir, -3) == plyer && getLine(p, dir, -4) == plyer && (getLine(p, dir, -5) == plyer.Conversion() || getLine(p, dir, -5) == -1) {
+			value += w.PointDeadFourA
+			if me != plyer {
+				value -= w.PointFourOpponent
+			}
+			continue
+		}
+		// 死四B 111*1
+		if getLine(p, dir, -1) == plyer && getLine(p, dir, -2) == plyer && getLine(p, dir, -3) == plyer && getLine(p, dir, 1) == plyer {
+			value += w.PointDeadFourB
+			if me != plyer {
+				value -= w.PointFourOpponent
+			}
+			continue
+		}
+		// 死四C 11*11
+		if getLine(p, dir, -1) == plyer && getLine(p, dir, -2) == plyer && getLine(p, dir, 1) == plyer && getLine(p, dir, 2) == plyer {
+			value += w.PointDeadFourC
+			if me != plyer {
+				value -= w.PointFourOpponent
+			}
+			continue
+		}
+		// 活三 近3位置 111*0
+		if getLine(p, dir, -1) == plyer && getLine(p, dir, -2) == plyer && getLine(p, dir, -3) == plyer {
+			if getLine(p, dir, 1) == 0 {
+				value += w.PointNearThree
+				if getLine(p, dir, -4) == 0 {
+					value += w.PointNearLiveThree
+					if me != plyer {
+						value -= w.PointNearLiveThreeOpponent
+					}
+				}
+			}
+			if (getLine(p, dir, 1) == plyer.Conversion() || getLine(p, dir, 1) == -1) && getLine(p, dir, -4) == 0 {
+				value += w.PointNearSleepThree
+			}
+			if (getLine(p, dir, -4) == plyer.Conversion() || getLine(p, dir, -4) == -1) && getLine(p, dir, 1) == 0 {
+				value += w.PointNearSleepThree
+			}
+			continue
+		}
+		// 活三 远3位置 1110*
+		if getLine(p, dir, -1) == 0 && getLine(p, dir, -2) == plyer && getLine(p, dir, -3) == plyer && getLine(p, dir, -4) == plyer {
+			value += w.PointFarThree
+			continue
+		}
+		// 死三 11*1
+		if getLine(p, dir, -1) == plyer && getLine(p, dir, -2) == plyer && getLine(p, dir, 1) == plyer {
+			value += w.PointSplitThree
+			if getLine(p, dir, -3) == 0 && getLine(p, dir, 2) == 0 {
+				value += w.PointSplitLiveThree
+				continue
+			}
+			if (getLine(p, dir, -3) == plyer.Conversion() || getLine(p, dir, -3) == -1) && (getLine(p, dir, 2) == plyer.Conversion() || getLine(p, dir, 2) == -1) {
+				value -= w.PointSplitThree
+				continue
+			} else {
+				value += w.PointSplitSleepThree
+				continue
+			}
+		}
+		// 活二的个数（因为会算2次，就2倍）
+		if getLine(p, dir, -1) == plyer && getLine(p, dir, -2) == plyer && getLine(p, dir, -3) == 0 && getLine(p, dir, 1) == 0 {
+			if getLine(p, dir, 2) == 0 || getLine(p, dir, -4) == 0 {
+				numoftwo += 2
+			} else {
+				value += w.PointSleepTwo
+			}
+		}
+		if getLine(p, dir, -1) == plyer && getLine(p, dir, -2) == 0 && getLine(p, dir, 2) == plyer && getLine(p, dir, 1) == 0 && getLine(p, dir, 3) == 0 {
+			numoftwo += 2
+		}
+		if getLine(p, dir, -1) == 0 && getLine(p, dir, 4) == 0 && getLine(p, dir, 3) == plyer && (getLine(p, dir, 2) == plyer && getLine(p, dir, 1) == 0 || getLine(p, dir, 1) == plyer && getLine(p, dir, 2) == 0) {
+			numoftwo += 2
+		}
+		if getLine(p, dir, -1) == plyer && getLine(p, dir, 1) == plyer && getLine(p, dir, -2) == 0 && getLine(p, dir, 2) == 0 {
+			if getLine(p, dir, 3) == 0 || getLine(p, dir, -3) == 0 {
+				numoftwo++
+			} else {
+				value += w.PointSleepSplitTwo
+			}
+		}
+		// 其余散棋
+		numOfplyer := 0
+		for k := -4; k <= 0; k++ { // ++++* +++*+ ++*++ +*+++ *++++
+			temp := 0
+			for l := 0; l <= 4; l++ {
+				if getLine(p, dir, k+l) == plyer {
+					temp += 5 - abs(k+l)
+				} else if getLine(p, dir, k+l) == plyer.Conversion() || getLine(p, dir, k+l) == -1 {
+					temp = 0
+					break
+				}
+			}
+			numOfplyer += temp
+		}
+		value += numOfplyer * w.PointScatter
+	}
+	numoftwo /= 2
+	if numoftwo >= 2 {
+		value += w.PointDoubleTwo
+		if me != plyer {
+			value -= w.PointDoubleTwoOpponent
+		}
+	} else if numoftwo == 1 {
+		value += w.PointSingleTwo
+		if me != plyer {
+			value -= w.PointSingleTwoOpponent
+		}
+	}
+	return
+}
+
+// 局面上color方的棋形得分，成五按规则判断
+func (r *Robot) evaluateBoard(color board.Color) (values int) {
+	w := r.weights
+	p := board.Point{}
+	for i := 0; i < board.Size; i++ {
+		for j := 0; j < board.Size; j++ {
+			p.X, p.Y = j, i
+			if r.get(p) != color {
+				continue
+			}
+			for _, dir := range board.EightDirections {
+				colors := make([]board.Color, 9)
+				for k := 0; k < 9; k++ {
+					pk := p.Move(dir, k-4)
+					if pk.CheckRange() {
+						colors[k] = r.get(pk)
+					} else {
+						colors[k] = board.Color(-1)
+					}
+				}
+				if colors[5] == color && colors[6] == color && colors[7] == color && colors[8] == color {
+					// 不允许长连的规则下，六个或更多连在一起不算成五
+					p5 := p.Move(dir, 5)
+					overline := colors[3] == color || p5.CheckRange() && r.get(p5) == color
+					if !overline || r.rule.AllowsOverline(color) {
+						values += w.BoardFive
+					}
+					continue
+				}
+				if colors[5] == color && colors[6] == color && colors[7] == color && colors[3] == 0 {
+					if colors[8] == 0 { //?AAAA?
+						values += w.BoardLiveFour
+					} else if colors[8] != color { //AAAA?
+						values += w.BoardDeadFour
+					}
+					continue
+				}
+				if colors[5] == color && colors[6] == color {
+					if colors[7] == 0 && colors[8] == color { //AAA?A
+						values += w.BoardSplitFour
+						continue
+					}
+					if colors[3] == 0 && colors[7] == 0 {
+						if colors[2] == 0 && colors[8] != color || colors[8] == 0 && colors[2] != color { //??AAA??
+							values += w.BoardLiveThree
+						} else if colors[2] != color && colors[2] != 0 && colors[8] != color && colors[8] != 0 { //?AAA?
+							values += w.BoardBlockedThree
+						}
+						continue
+					}
+					if colors[3] != 0 && colors[3] != color && colors[7] == 0 && colors[8] == 0 { //AAA??
+						values += w.BoardSleepThree
+						continue
+					}
+				}
+				if colors[5] == color && colors[6] == 0 && colors[7] == color && colors[8] == color { //AA?AA
+					values += w.BoardMiddleSplitFour
+					continue
+				}
+				if colors[5] == 0 && colors[6] == color && colors[7] == color {
+					if colors[3] == 0 && colors[8] == 0 { //?A?AA?
+						values += w.BoardSplitLiveThree
+					} else if (colors[3] != 0 && colors[3] != color && colors[8] == 0) || (colors[8] != 0 && colors[8] != color && colors[3] == 0) { //A?AA? ?A?AA
+						values += w.BoardSplitSleepThree
+					}
+					continue
+				}
+				if colors[5] == 0 && colors[8] == color {
+					if colors[6] == 0 && colors[7] == color { //A??AA
+						values += w.BoardGapSleepThree
+					} else if colors[6] == color && colors[7] == 0 { //A?A?A
+						values += w.BoardDoubleGapThree
+					}
+					continue
+				}
+				if colors[5] == color {
+					if colors[3] == 0 && colors[6] == 0 {
+						if colors[1] == 0 && colors[2] == 0 && colors[7] != 0 && colors[7] != color || colors[8] == 0 && colors[7] == 0 && colors[2] != 0 && colors[2] != color { //??AA??
+							values += w.BoardLiveTwo
+						} else if colors[2] != 0 && colors[2] != color && colors[7] == 0 && colors[8] != 0 && colors[8] != color { //?AA??
+							values += w.BoardBlockedTwo
+						}
+					} else if colors[3] != 0 && colors[3] != color && colors[6] == 0 && colors[7] == 0 && colors[8] == 0 { //AA???
+						values += w.BoardSleepTwo
+					}
+					continue
+				}
+				if colors[5] == 0 && colors[6] == color {
+					if colors[3] == 0 && colors[7] == 0 {
+						if colors[2] != 0 && colors[2] != color && colors[8] == 0 || colors[2] == 0 && colors[8] != 0 && colors[8] != color { //??A?A??
+							values += w.BoardSplitLiveTwo
+						}
+						if colors[2] != 0 && colors[2] != color && colors[8] != 0 && colors[8] != color { //?A?A?
+							values += w.BoardSplitBlockedTwo
+						}
+					} else if colors[3] != 0 && colors[3] != color && colors[7] == 0 && colors[8] == 0 { //A?A??
+						values += w.BoardSplitSleepTwo
+					}
+					continue
+				}
+				if colors[5] == 0 && colors[6] == 0 && colors[7] == color {
+					if colors[3] == 0 && colors[8] == 0 { //?A??A?
+						values += w.BoardFarLiveTwo
+						continue
+					}
+					if colors[3] != 0 && colors[3] != color && colors[8] == 0 { //A??A?
+						p5 := p.Move(dir, 5)
+						if p5.CheckRange() {
+							color5 := r.get(p5)
+							if color5 == 0 {
+								values += w.BoardFarSleepTwo
+							} else if color5 != color {
+								values += w.BoardFarBlockedTwo
+							}
+						}
+					}
+					continue
+				}
+			}
+		}
+	}
+	return values
+}
+
+type pointAndValue struct {
+	p     board.Point
+	Value int
+}
+
+type pointAndValueSlice []*pointAndValue
+
+func (s pointAndValueSlice) Len() int {
+	return len(s)
+}
+
+func (s pointAndValueSlice) Less(i, j int) bool {
+	return s[i].Value > s[j].Value
+}
+
+func (s pointAndValueSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s pointAndValueSlice) filter(f func(p board.Point) bool) pointAndValueSlice {
+	var result pointAndValueSlice
+	for _, obj := range s {
+		if f(obj.p) {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
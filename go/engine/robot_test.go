@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/game"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	Log = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	os.Exit(m.Run())
+}
+
+// 不会故意走错的机器人，按moves（黑先交替）摆好局面
+func newTestRobot(t *testing.T, color board.Color, level Difficulty, rule board.Rule, moves string) *Robot {
+	t.Helper()
+	points, err := board.ParseMoves(moves, board.Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRobot(color, level)
+	r.blunderRate = 0
+	r.Notify(game.GameStarted{Rule: rule, Size: board.Size})
+	c := board.Black
+	for i, p := range points {
+		r.Notify(game.MovePlayed{Color: c, P: p, Number: i + 1})
+		c = c.Conversion()
+	}
+	return r
+}
+
+func TestRecordCutoff(t *testing.T) {
+	r := newTestRobot(t, board.Black, Normal, board.Freestyle, "")
+	a, b, c := board.Point{X: 1, Y: 1}, board.Point{X: 2, Y: 2}, board.Point{X: 3, Y: 3}
+	for _, p := range []board.Point{a, b, a, c} {
+		r.recordCutoff(3, p)
+	}
+	r.recordCutoff(2, a)
+	// 每层最多两个杀手着法，新的排在前面，已经是杀手的不重复记录
+	if want := []board.Point{c, b}; !reflect.DeepEqual(r.killers[3], want) {
+		t.Errorf("killers at step 3 = %v, want %v", r.killers[3], want)
+	}
+	if !r.isKiller(2, a) || r.isKiller(2, b) || r.isKiller(4, a) {
+		t.Errorf("isKiller mixes up the steps: %v", r.killers)
+	}
+	for p, want := range map[board.Point]int{a: 3*3*2 + 2*2, b: 3 * 3, c: 3 * 3} {
+		if got := r.history[p.Hash()]; got != want {
+			t.Errorf("history[%s] = %d, want %d", p, got, want)
+		}
+	}
+}
+
+func TestOrderMoves(t *testing.T) {
+	r := newTestRobot(t, board.Black, Normal, board.Freestyle, "h8")
+	high, mid, low := board.Point{X: 1, Y: 1}, board.Point{X: 2, Y: 2}, board.Point{X: 3, Y: 3}
+	best, killer := board.Point{X: 4, Y: 4}, board.Point{X: 5, Y: 5}
+	r.putIntoCache(&r.boardStatus, 2, &pointAndValue{best, 0})
+	r.recordCutoff(3, killer)
+	r.history[killer.Hash()] = 0
+	r.history[low.Hash()] = 200
+	newQueue := func() pointAndValueSlice {
+		return pointAndValueSlice{{mid, 50}, {best, 10}, {high, 100}, {killer, 5}, {low, 20}}
+	}
+	tests := []struct {
+		step int
+		want []board.Point
+	}{
+		// 置换表的最佳着法、杀手着法在最前，其余按静态评分加历史得分
+		{3, []board.Point{best, killer, low, high, mid}},
+		// 第2层没有杀手着法
+		{2, []board.Point{best, low, high, mid, killer}},
+		// 最后一层只看静态评分
+		{1, []board.Point{high, mid, low, best, killer}},
+	}
+	for _, tt := range tests {
+		queue := newQueue()
+		r.orderMoves(queue, tt.step)
+		var got []board.Point
+		for _, obj := range queue {
+			got = append(got, obj.p)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("step %d: order %v, want %v", tt.step, got, tt.want)
+		}
+	}
+}
+
+// 固定的开局上让两个机器人各走3步，统计搜索的节点数。
+// 改变搜索或排序的结果时这里的数字也会变，确认变化是预期的之后再更新
+func TestSearchNodeCounts(t *testing.T) {
+	tests := []struct {
+		opening string
+		nodes   int
+	}{
+		{"h8i9", 958},
+		{"h8i7j8", 850},
+		{"h8h9i8", 948},
+	}
+	for _, tt := range tests {
+		moves, err := board.ParseMoves(tt.opening, board.Size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := game.NewGame(board.Freestyle)
+		robots := make(map[board.Color]*Robot)
+		for _, color := range []board.Color{board.Black, board.White} {
+			r := NewRobot(color, Normal)
+			r.maxCheckmateCount, r.blunderRate = 0, 0 // 只统计博弈树搜索
+			robots[color] = r
+			g.Subscribe(r)
+		}
+		g.Start(board.Empty, 0)
+		for _, p := range moves {
+			if err := g.Play(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		nodes := 0
+		for i := 0; i < 6; i++ {
+			r := robots[g.WhoseTurn()]
+			p, err := r.Play()
+			if err != nil {
+				t.Fatal(err)
+			}
+			nodes += r.LastAnalysis().Stats.Nodes
+			if err := g.Play(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if nodes != tt.nodes {
+			t.Errorf("%s: searched %d nodes, want %d", tt.opening, nodes, tt.nodes)
+		}
+	}
+}
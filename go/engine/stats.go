@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Log 是引擎日志，每行一个JSON对象
+var Log = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+// SearchStats 是一次搜索的统计信息
+type SearchStats struct {
+	Nodes        int
+	CacheHits    int
+	CacheMisses  int
+	Depth        int
+	KillAttempts int // calculateKill的调用次数
+	Elapsed      time.Duration
+}
+
+func (s SearchStats) nodesPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Nodes) / s.Elapsed.Seconds()
+}
+
+// position是搜索的局面，报告问题时可以用board.ParsePosition还原
+func (s SearchStats) log(level slog.Level, msg string, color board.Color, position *board.Position) {
+	Log.LogAttrs(context.Background(), level, msg,
+		slog.String("color", color.String()),
+		slog.String("position", position.String()),
+		slog.Int("nodes", s.Nodes),
+		slog.Int("cache_hits", s.CacheHits),
+		slog.Int("cache_misses", s.CacheMisses),
+		slog.Int("depth", s.Depth),
+		slog.Int("kill_attempts", s.KillAttempts),
+		slog.Int64("elapsed_ms", s.Elapsed.Milliseconds()),
+		slog.Float64("nps", s.nodesPerSecond()),
+	)
+}
+
+func (s SearchStats) String() string {
+	return fmt.Sprintf("depth %d, %d nodes, %d/%d cache hits, %d kill attempts, %v, %.0f nps",
+		s.Depth, s.Nodes, s.CacheHits, s.CacheHits+s.CacheMisses, s.KillAttempts, s.Elapsed.Round(time.Millisecond), s.nodesPerSecond())
+}
@@ -0,0 +1,53 @@
+package engine
+
+import "github.com/CuteReimu/gobang/board"
+
+// 棋盘的8种对称变换（二面体群D4），0是恒等变换
+type symmetry int
+
+const symmetryCount = 8
+
+func (s symmetry) apply(p board.Point) board.Point {
+	n := board.Size - 1
+	switch s {
+	case 0:
+		return p
+	case 1:
+		return board.Point{X: n - p.X, Y: p.Y}
+	case 2:
+		return board.Point{X: p.X, Y: n - p.Y}
+	case 3:
+		return board.Point{X: n - p.X, Y: n - p.Y}
+	case 4:
+		return board.Point{X: p.Y, Y: p.X}
+	case 5:
+		return board.Point{X: n - p.Y, Y: p.X}
+	case 6:
+		return board.Point{X: p.Y, Y: n - p.X}
+	case 7:
+		return board.Point{X: n - p.Y, Y: n - p.X}
+	}
+	panic("unreachable")
+}
+
+func (s symmetry) inverse() symmetry {
+	switch s {
+	case 5:
+		return 6
+	case 6:
+		return 5
+	}
+	return s
+}
+
+// 返回8种对称变换下最小的哈希值，以及得到这个哈希值所用的变换。
+// 把原棋盘上的着法p变换到归一化的方向用s.apply(p)，变换回来用s.inverse().apply(p)
+func (b *boardStatus) canonicalHash() (uint64, symmetry) {
+	best := symmetry(0)
+	for s := symmetry(1); s < symmetryCount; s++ {
+		if b.hashes[s] < b.hashes[best] {
+			best = s
+		}
+	}
+	return b.hashes[best], best
+}
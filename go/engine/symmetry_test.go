@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"testing"
+)
+
+func TestSymmetryInverse(t *testing.T) {
+	defer func(size int) { board.Size = size }(board.Size)
+	for _, size := range []int{15, 19} {
+		board.Size = size
+		n := size - 1
+		for s := symmetry(0); s < symmetryCount; s++ {
+			for _, p := range []board.Point{{X: 0, Y: 0}, {X: n, Y: 0}, {X: 3, Y: 11}, {X: n, Y: n - 2}, {X: n / 2, Y: n / 2}} {
+				q := s.apply(p)
+				if !q.CheckRange() {
+					t.Errorf("size %d: symmetry %d moves %s off the board to %s", size, s, p, q)
+				}
+				if back := s.inverse().apply(q); back != p {
+					t.Errorf("size %d: symmetry %d maps %s to %s and back to %s", size, s, p, q, back)
+				}
+			}
+		}
+	}
+}
+
+func TestCanonicalHashUnderSymmetry(t *testing.T) {
+	// 没有对称性的局面，不同的变换得到不同的棋盘
+	black := []board.Point{{X: 7, Y: 7}, {X: 8, Y: 6}, {X: 9, Y: 7}}
+	white := []board.Point{{X: 8, Y: 8}, {X: 6, Y: 5}}
+	best := board.Point{X: 10, Y: 8}
+	build := func(s symmetry) *boardStatus {
+		b := &boardStatus{}
+		b.initBoardStatus()
+		for _, p := range black {
+			b.set(s.apply(p), board.Black)
+		}
+		for _, p := range white {
+			b.set(s.apply(p), board.White)
+		}
+		return b
+	}
+	original := build(0)
+	hash, _ := original.canonicalHash()
+	cache := make(boardCache)
+	cache.putIntoCache(original, 3, &pointAndValue{best, 42})
+	for s := symmetry(0); s < symmetryCount; s++ {
+		b := build(s)
+		if h, _ := b.canonicalHash(); h != hash {
+			t.Errorf("symmetry %d: canonical hash %x, want %x", s, h, hash)
+		}
+		v := cache.getFromCache(b, 3)
+		if v == nil || v.p != s.apply(best) || v.Value != 42 {
+			t.Errorf("symmetry %d: cached %v, want %s with value 42", s, v, s.apply(best))
+		}
+		if p, ok := cache.getBestMove(b); !ok || p != s.apply(best) {
+			t.Errorf("symmetry %d: best move %s, %t; want %s", s, p, ok, s.apply(best))
+		}
+		// 变换之后的局面存进去，在原来的局面上取出来也要换回原来的方向
+		other := make(boardCache)
+		other.putIntoCache(b, 1, &pointAndValue{s.apply(best), 7})
+		if v := other.getFromCache(original, 1); v == nil || v.p != best {
+			t.Errorf("symmetry %d: stored from the transformed board, got %v back, want %s", s, v, best)
+		}
+	}
+	for _, p := range append(black, white...) {
+		original.set(p, board.Empty)
+	}
+	if h, _ := original.canonicalHash(); h != 0 {
+		t.Errorf("hash after clearing the board = %x, want 0", h)
+	}
+}
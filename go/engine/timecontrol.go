@@ -0,0 +1,48 @@
+package engine
+
+import "time"
+
+// SetClock 根据剩余时间分配本步的思考时间，剩余30步左右的份额再加上大部分增量
+func (r *Robot) SetClock(remaining, increment time.Duration) {
+	t := remaining/30 + increment*3/4
+	if limit := remaining / 3; t > limit {
+		t = limit
+	}
+	if t < 10*time.Millisecond {
+		t = 10 * time.Millisecond
+	}
+	r.moveTime = t
+}
+
+// 有时间限制时逐层加深搜索，超时则中止并返回上一层完整搜索的结果
+func (r *Robot) deepen() (*pointAndValue, int) {
+	if r.moveTime <= 0 {
+		return r.max(r.maxLevelCount, 100000000), r.maxLevelCount
+	}
+	fired := make(chan struct{})
+	timer := time.AfterFunc(r.moveTime, func() {
+		r.stopSearch.Store(true)
+		close(fired)
+	})
+	defer func() {
+		if !timer.Stop() {
+			<-fired // 回调已经在运行，等它设置完再清除，否则下一次搜索一开始就会被中止
+		}
+		r.stopSearch.Store(false)
+	}()
+	var best *pointAndValue
+	depth := 0
+	for d := 1; d <= r.maxLevelCount; d++ {
+		result := r.max(d, 100000000)
+		if result == nil {
+			break
+		}
+		best, depth = result, d
+	}
+	return best, depth
+}
+
+// Interrupt 中止正在进行的搜索，让Play尽快返回
+func (r *Robot) Interrupt() {
+	r.stopSearch.Store(true)
+}
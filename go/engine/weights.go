@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+)
+
+// EvalWeights 是估值函数的权重，Point开头的用于evaluatePoint2，Board开头的用于evaluateBoard。
+// 注释中A代表己方的子，?代表空位，*代表当前评估的空位，1代表plyer的子
+type EvalWeights struct {
+	PointLiveFour              int `json:"point_live_four"`                // 01111*
+	PointDeadFourA             int `json:"point_dead_four_a"`              // 21111*
+	PointDeadFourB             int `json:"point_dead_four_b"`              // 111*1
+	PointDeadFourC             int `json:"point_dead_four_c"`              // 11*11
+	PointFourOpponent          int `json:"point_four_opponent"`            // 对方的四，在上面的基础上减去
+	PointNearThree             int `json:"point_near_three"`               // 111*0
+	PointNearLiveThree         int `json:"point_near_live_three"`          // 0111*0
+	PointNearLiveThreeOpponent int `json:"point_near_live_three_opponent"` // 对方的活三，在上面的基础上减去
+	PointNearSleepThree        int `json:"point_near_sleep_three"`         // 2111*0 0111*2
+	PointFarThree              int `json:"point_far_three"`                // 1110*
+	PointSplitThree            int `json:"point_split_three"`              // 11*1
+	PointSplitLiveThree        int `json:"point_split_live_three"`         // 011*10
+	PointSplitSleepThree       int `json:"point_split_sleep_three"`        // 211*10
+	PointSleepTwo              int `json:"point_sleep_two"`                // 211*0
+	PointSleepSplitTwo         int `json:"point_sleep_split_two"`          // 201*102
+	PointScatter               int `json:"point_scatter"`                  // 其余散棋
+	PointDoubleTwo             int `json:"point_double_two"`               // 两个以上活二
+	PointDoubleTwoOpponent     int `json:"point_double_two_opponent"`
+	PointSingleTwo             int `json:"point_single_two"` // 一个活二
+	PointSingleTwoOpponent     int `json:"point_single_two_opponent"`
+	BoardFive                  int `json:"board_five"`              // AAAAA
+	BoardLiveFour              int `json:"board_live_four"`         // ?AAAA?
+	BoardDeadFour              int `json:"board_dead_four"`         // AAAA?
+	BoardSplitFour             int `json:"board_split_four"`        // AAA?A
+	BoardLiveThree             int `json:"board_live_three"`        // ??AAA??
+	BoardBlockedThree          int `json:"board_blocked_three"`     // ?AAA?
+	BoardSleepThree            int `json:"board_sleep_three"`       // AAA??
+	BoardMiddleSplitFour       int `json:"board_middle_split_four"` // AA?AA
+	BoardSplitLiveThree        int `json:"board_split_live_three"`  // ?A?AA?
+	BoardSplitSleepThree       int `json:"board_split_sleep_three"` // A?AA? ?A?AA
+	BoardGapSleepThree         int `json:"board_gap_sleep_three"`   // A??AA
+	BoardDoubleGapThree        int `json:"board_double_gap_three"`  // A?A?A
+	BoardLiveTwo               int `json:"board_live_two"`          // ??AA??
+	BoardBlockedTwo            int `json:"board_blocked_two"`       // ?AA??
+	BoardSleepTwo              int `json:"board_sleep_two"`         // AA???
+	BoardSplitLiveTwo          int `json:"board_split_live_two"`    // ??A?A??
+	BoardSplitBlockedTwo       int `json:"board_split_blocked_two"` // ?A?A?
+	BoardSplitSleepTwo         int `json:"board_split_sleep_two"`   // A?A??
+	BoardFarLiveTwo            int `json:"board_far_live_two"`      // ?A??A?
+	BoardFarSleepTwo           int `json:"board_far_sleep_two"`     // A??A??
+	BoardFarBlockedTwo         int `json:"board_far_blocked_two"`   // A??A?
+}
+
+// DefaultEvalWeights 返回默认的权重
+func DefaultEvalWeights() *EvalWeights {
+	return &EvalWeights{
+		PointLiveFour:              300000,
+		PointDeadFourA:             250000,
+		PointDeadFourB:             240000,
+		PointDeadFourC:             230000,
+		PointFourOpponent:          500,
+		PointNearThree:             1450,
+		PointNearLiveThree:         6000,
+		PointNearLiveThreeOpponent: 300,
+		PointNearSleepThree:        500,
+		PointFarThree:              350,
+		PointSplitThree:            700,
+		PointSplitLiveThree:        6700,
+		PointSplitSleepThree:       800,
+		PointSleepTwo:              250,
+		PointSleepSplitTwo:         125,
+		PointScatter:               5,
+		PointDoubleTwo:             3000,
+		PointDoubleTwoOpponent:     100,
+		PointSingleTwo:             2725,
+		PointSingleTwoOpponent:     10,
+		BoardFive:                  1000000,
+		BoardLiveFour:              300000 / 2,
+		BoardDeadFour:              25000,
+		BoardSplitFour:             30000,
+		BoardLiveThree:             22000 / 2,
+		BoardBlockedThree:          500 / 2,
+		BoardSleepThree:            500,
+		BoardMiddleSplitFour:       26000 / 2,
+		BoardSplitLiveThree:        22000,
+		BoardSplitSleepThree:       800,
+		BoardGapSleepThree:         600,
+		BoardDoubleGapThree:        550 / 2,
+		BoardLiveTwo:               650 / 2,
+		BoardBlockedTwo:            150,
+		BoardSleepTwo:              150,
+		BoardSplitLiveTwo:          250 / 2,
+		BoardSplitBlockedTwo:       150 / 2,
+		BoardSplitSleepTwo:         150,
+		BoardFarLiveTwo:            200 / 2,
+		BoardFarSleepTwo:           200,
+		BoardFarBlockedTwo:         150,
+	}
+}
+
+// Params 按字段顺序返回指向各个权重的指针，用于调参。BoardFive是胜负的分数，搜索按它判断成五和认输，不参与调参
+func (w *EvalWeights) Params() []*int {
+	v := reflect.ValueOf(w).Elem()
+	var ps []*int
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Name != "BoardFive" {
+			ps = append(ps, v.Field(i).Addr().Interface().(*int))
+		}
+	}
+	return ps
+}
+
+// LoadEvalWeights 从JSON文件读取权重，文件中没有写的权重使用默认值
+func LoadEvalWeights(name string) (*EvalWeights, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	w := DefaultEvalWeights()
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Save 把权重写入JSON文件
+func (w *EvalWeights) Save(name string) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0644)
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"time"
+)
+
+// Event 是对局中发生的事件，具体类型是下面几种之一，观察者用type switch区分
+type Event interface {
+	event()
+}
+
+// GameStarted 在清空棋盘开始新的一局时发出
+type GameStarted struct {
+	Rule          board.Rule
+	Size          int
+	HandicapColor board.Color // 被让子的一方，开局可以连续下Handicap个子
+	Handicap      int
+}
+
+// MovePlayed 在落子之后发出，Number是这一步的序号，从1开始
+type MovePlayed struct {
+	Color  board.Color
+	P      board.Point
+	Number int
+}
+
+// MoveUndone 在悔棋之后发出，Color和P是被撤销的那一步
+type MoveUndone struct {
+	Color board.Color
+	P     board.Point
+}
+
+// MoveRejected 在Play拒绝不合法的着法之后发出，Err包装了ErrOutOfBoard等错误
+type MoveRejected struct {
+	Color board.Color
+	P     board.Point
+	Err   error
+}
+
+// DrawOffered 在Color方提和、对方作出回应之后发出
+type DrawOffered struct {
+	Color    board.Color
+	Accepted bool
+}
+
+// GameOver 在对局结束时发出，和棋时Winner是board.Empty
+type GameOver struct {
+	Winner board.Color
+	Reason string
+}
+
+// ClockTick 在有时间限制的对局中发出，思考时每秒一次，落子之后也会立即发出一次
+type ClockTick struct {
+	Turn         board.Color
+	Black, White time.Duration // 双方的剩余时间
+	AfterMove    bool          // 是否是落子之后的那一次
+}
+
+func (GameStarted) event()  {}
+func (MovePlayed) event()   {}
+func (MoveUndone) event()   {}
+func (MoveRejected) event() {}
+func (DrawOffered) event()  {}
+func (GameOver) event()     {}
+func (ClockTick) event()    {}
+
+// Observer 接收对局事件，玩家、界面、日志和记录器都通过它观察对局
+type Observer interface {
+	Notify(e Event)
+}
+
+// ObserverFunc 把普通函数当作Observer使用
+type ObserverFunc func(e Event)
+
+func (f ObserverFunc) Notify(e Event) {
+	f(e)
+}
@@ -0,0 +1,287 @@
+// Package game 负责一局棋的进行：维护棋盘和轮次、判定胜负、通知观察者，并驱动双方玩家下棋。
+package game
+
+import (
+	"errors"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"slices"
+	"sync"
+)
+
+// Play拒绝不合法的着法时返回的错误，用errors.Is区分
+var (
+	ErrGameOver   = errors.New("game is over")
+	ErrOutOfBoard = errors.New("out of board")
+	ErrOccupied   = errors.New("occupied")
+	ErrForbidden  = errors.New("forbidden move")
+)
+
+type gameMove struct {
+	p        board.Point
+	color    board.Color
+	handicap int // 这一步之前剩余的让子数，悔棋时恢复
+}
+
+// Game 是一局棋的权威状态：棋盘、轮到谁、胜负判定，界面和网络等前端都通过它来下棋。
+// 所有方法都可以在不同的goroutine中调用
+type Game struct {
+	mu            sync.Mutex
+	rule          board.Rule
+	board         [][]board.Color
+	moves         []gameMove
+	turn          board.Color
+	handicapColor board.Color // 被让子的一方，开局可以连续下handicap个子
+	handicap      int
+	startHandicap int // 开局时的让子数，Replay时用
+	over          bool
+	winner        board.Color
+	reason        string
+	observers     []subscription
+	nextID        int
+}
+
+type subscription struct {
+	id int
+	o  Observer
+}
+
+// NewGame 按rule创建一局棋，黑方先下
+func NewGame(rule board.Rule) *Game {
+	g := &Game{rule: rule}
+	g.reset()
+	return g
+}
+
+func (g *Game) reset() {
+	g.board = make([][]board.Color, board.Size)
+	for i := 0; i < board.Size; i++ {
+		g.board[i] = make([]board.Color, board.Size)
+	}
+	g.moves = nil
+	g.turn = board.Black
+	g.handicap = 0
+	g.over = false
+	g.winner = board.Empty
+	g.reason = ""
+}
+
+// Subscribe 订阅对局事件，返回取消订阅的函数。
+// 事件在引起它的goroutine中按订阅的顺序依次通知，观察者中可以查询对局状态，但不能再修改对局
+func (g *Game) Subscribe(o Observer) (cancel func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	id := g.nextID
+	g.nextID++
+	g.observers = append(g.observers, subscription{id, o})
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.observers = slices.DeleteFunc(slices.Clone(g.observers), func(s subscription) bool { return s.id == id })
+	}
+}
+
+func (g *Game) publish(e Event) {
+	g.mu.Lock()
+	observers := g.observers // 取消订阅时会复制一份，这里不需要复制
+	g.mu.Unlock()
+	for _, s := range observers {
+		s.o.Notify(e)
+	}
+}
+
+// Start 清空棋盘开始新的一局，handicapColor一方开局可以多下handicap个子
+func (g *Game) Start(handicapColor board.Color, handicap int) {
+	g.mu.Lock()
+	g.reset()
+	g.handicapColor = handicapColor
+	g.handicap = handicap
+	g.startHandicap = handicap
+	g.mu.Unlock()
+	g.publish(GameStarted{Rule: g.rule, Size: board.Size, HandicapColor: handicapColor, Handicap: handicap})
+}
+
+// Validate 检查当前行棋方能不能在p处落子，不能时返回的错误包装了ErrGameOver、ErrOutOfBoard、ErrOccupied或ErrForbidden
+func (g *Game) Validate(p board.Point) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.validate(p)
+}
+
+func (g *Game) validate(p board.Point) error {
+	switch {
+	case g.over:
+		return ErrGameOver
+	case !p.CheckRange():
+		return fmt.Errorf("%w: %s", ErrOutOfBoard, p)
+	case g.board[p.Y][p.X] != board.Empty:
+		return fmt.Errorf("%w: %s%s", ErrOccupied, p, g.board[p.Y][p.X])
+	case g.rule.IsForbidden(g.board, p, g.turn):
+		return fmt.Errorf("%w: %s%s", ErrForbidden, g.turn, p)
+	}
+	return nil
+}
+
+// Play 让当前行棋方在p处落子，不合法时发出MoveRejected并返回Validate的错误，不改变状态
+func (g *Game) Play(p board.Point) error {
+	g.mu.Lock()
+	if err := g.validate(p); err != nil {
+		color := g.turn
+		g.mu.Unlock()
+		g.publish(MoveRejected{Color: color, P: p, Err: err})
+		return err
+	}
+	color := g.turn
+	g.board[p.Y][p.X] = color
+	g.moves = append(g.moves, gameMove{p, color, g.handicap})
+	if g.handicap > 0 && color == g.handicapColor {
+		g.handicap--
+	} else {
+		g.turn = color.Conversion()
+	}
+	if g.rule.IsWin(g.board, p) {
+		g.over, g.winner, g.reason = true, color, "five"
+	} else if len(g.moves) == board.Size*board.Size {
+		g.over, g.winner, g.reason = true, board.Empty, "full board"
+	} else if !g.rule.FivePossible(g.board, board.Black) && !g.rule.FivePossible(g.board, board.White) {
+		g.over, g.winner, g.reason = true, board.Empty, "dead draw"
+	}
+	over, winner, reason, number := g.over, g.winner, g.reason, len(g.moves)
+	g.mu.Unlock()
+	g.publish(MovePlayed{Color: color, P: p, Number: number})
+	if over {
+		g.publish(GameOver{Winner: winner, Reason: reason})
+	}
+	return nil
+}
+
+// Forfeit 判当前行棋方负，reason是原因，例如超时
+func (g *Game) Forfeit(reason string) {
+	g.mu.Lock()
+	if g.over {
+		g.mu.Unlock()
+		return
+	}
+	g.over, g.winner, g.reason = true, g.turn.Conversion(), reason
+	winner := g.winner
+	g.mu.Unlock()
+	g.publish(GameOver{Winner: winner, Reason: reason})
+}
+
+// Draw 以和棋结束对局，reason是原因，例如双方同意
+func (g *Game) Draw(reason string) {
+	g.mu.Lock()
+	if g.over {
+		g.mu.Unlock()
+		return
+	}
+	g.over, g.winner, g.reason = true, board.Empty, reason
+	g.mu.Unlock()
+	g.publish(GameOver{Winner: board.Empty, Reason: reason})
+}
+
+// End 直接判winner获胜，winner是board.Empty时为和棋，用于恢复棋谱里记下的结果
+func (g *Game) End(winner board.Color, reason string) {
+	g.mu.Lock()
+	if g.over {
+		g.mu.Unlock()
+		return
+	}
+	g.over, g.winner, g.reason = true, winner, reason
+	g.mu.Unlock()
+	g.publish(GameOver{Winner: winner, Reason: reason})
+}
+
+// Undo 撤销最后一步，已经结束的对局也可以悔棋继续
+func (g *Game) Undo() error {
+	g.mu.Lock()
+	if len(g.moves) == 0 {
+		g.mu.Unlock()
+		return errors.New("no move to undo")
+	}
+	m := g.moves[len(g.moves)-1]
+	g.moves = g.moves[:len(g.moves)-1]
+	g.board[m.p.Y][m.p.X] = board.Empty
+	g.turn = m.color
+	g.handicap = m.handicap
+	g.over, g.winner, g.reason = false, board.Empty, ""
+	g.mu.Unlock()
+	g.publish(MoveUndone{Color: m.color, P: m.p})
+	return nil
+}
+
+// WhoseTurn 返回轮到哪一方下
+func (g *Game) WhoseTurn() board.Color {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.turn
+}
+
+// At 返回p点的棋子
+func (g *Game) At(p board.Point) board.Color {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.board[p.Y][p.X]
+}
+
+// Result 返回对局是否结束，结束时还返回胜者，和棋为board.Empty
+func (g *Game) Result() (bool, board.Color) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.over, g.winner
+}
+
+// Reason 返回对局结束的原因，例如five、time、resign、dead draw，还没有结束时返回空字符串
+func (g *Game) Reason() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.reason
+}
+
+// Replay 只给o重新发一遍从GameStarted开始到现在的事件，让中途加入的观察者或者状态不同步的玩家跟上对局
+func (g *Game) Replay(o Observer) {
+	g.mu.Lock()
+	events := []Event{GameStarted{Rule: g.rule, Size: board.Size, HandicapColor: g.handicapColor, Handicap: g.startHandicap}}
+	for i, m := range g.moves {
+		events = append(events, MovePlayed{Color: m.color, P: m.p, Number: i + 1})
+	}
+	if g.over {
+		events = append(events, GameOver{Winner: g.winner, Reason: g.reason})
+	}
+	g.mu.Unlock()
+	for _, e := range events {
+		o.Notify(e)
+	}
+}
+
+// Moves 按顺序返回已经下过的全部着法
+func (g *Game) Moves() []board.Point {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	moves := make([]board.Point, len(g.moves))
+	for i, m := range g.moves {
+		moves[i] = m.p
+	}
+	return moves
+}
+
+// LastMove 返回最后一步，还没有落子时返回false
+func (g *Game) LastMove() (board.Point, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.moves) == 0 {
+		return board.Point{}, false
+	}
+	return g.moves[len(g.moves)-1].p, true
+}
+
+// Snapshot 返回棋盘的副本，调用者可以随意修改
+func (g *Game) Snapshot() [][]board.Color {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b := make([][]board.Color, board.Size)
+	for i := range b {
+		b[i] = append([]board.Color(nil), g.board[i]...)
+	}
+	return b
+}
@@ -0,0 +1,176 @@
+package game
+
+import (
+	"errors"
+	"github.com/CuteReimu/gobang/board"
+	"reflect"
+	"testing"
+)
+
+func pt(x, y int) board.Point {
+	return board.Point{X: x, Y: y}
+}
+
+func TestPlayValidateUndo(t *testing.T) {
+	type step struct {
+		undo     bool
+		p        board.Point
+		wantErr  error // Play和Validate的错误，悔棋时是Undo的错误是否为nil
+		wantTurn board.Color
+		wantOver bool
+	}
+	tests := []struct {
+		name  string
+		rule  board.Rule
+		steps []step
+	}{
+		{"alternate and reject", board.Freestyle, []step{
+			{p: pt(7, 7), wantTurn: board.White},
+			{p: pt(7, 7), wantErr: ErrOccupied, wantTurn: board.White},
+			{p: pt(-1, 3), wantErr: ErrOutOfBoard, wantTurn: board.White},
+			{p: pt(8, 8), wantTurn: board.Black},
+			{undo: true, wantTurn: board.White},
+			{undo: true, wantTurn: board.Black},
+			{undo: true, wantErr: errors.New("no move to undo"), wantTurn: board.Black},
+		}},
+		{"five ends the game and undo reopens it", board.Freestyle, []step{
+			{p: pt(0, 0), wantTurn: board.White}, {p: pt(0, 1), wantTurn: board.Black},
+			{p: pt(1, 0), wantTurn: board.White}, {p: pt(1, 1), wantTurn: board.Black},
+			{p: pt(2, 0), wantTurn: board.White}, {p: pt(2, 1), wantTurn: board.Black},
+			{p: pt(3, 0), wantTurn: board.White}, {p: pt(3, 1), wantTurn: board.Black},
+			{p: pt(4, 0), wantTurn: board.White, wantOver: true},
+			{p: pt(9, 9), wantErr: ErrGameOver, wantTurn: board.White, wantOver: true},
+			{undo: true, wantTurn: board.Black},
+			{p: pt(9, 9), wantTurn: board.White},
+		}},
+		{"renju forbids black double three", board.Renju, []step{
+			{p: pt(5, 7), wantTurn: board.White}, {p: pt(0, 0), wantTurn: board.Black},
+			{p: pt(6, 7), wantTurn: board.White}, {p: pt(0, 2), wantTurn: board.Black},
+			{p: pt(7, 5), wantTurn: board.White}, {p: pt(0, 4), wantTurn: board.Black},
+			{p: pt(7, 6), wantTurn: board.White}, {p: pt(0, 6), wantTurn: board.Black},
+			{p: pt(7, 7), wantErr: ErrForbidden, wantTurn: board.Black},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(tt.rule)
+			g.Start(board.Empty, 0)
+			for i, s := range tt.steps {
+				if s.undo {
+					if err := g.Undo(); (err == nil) != (s.wantErr == nil) {
+						t.Fatalf("step %d: Undo() = %v, want error %t", i, err, s.wantErr != nil)
+					}
+				} else {
+					validateErr := g.Validate(s.p)
+					if err := g.Play(s.p); !errors.Is(err, s.wantErr) || !errors.Is(validateErr, s.wantErr) {
+						t.Fatalf("step %d: Validate(%s) = %v, Play = %v, want %v", i, s.p, validateErr, err, s.wantErr)
+					}
+				}
+				if over, _ := g.Result(); g.WhoseTurn() != s.wantTurn || over != s.wantOver {
+					t.Fatalf("step %d: turn %s, over %t; want %s, %t", i, g.WhoseTurn(), over, s.wantTurn, s.wantOver)
+				}
+			}
+		})
+	}
+}
+
+func TestHandicapTurnOrder(t *testing.T) {
+	tests := []struct {
+		handicapColor board.Color
+		handicap      int
+		want          []board.Color // 前几步各是哪一方下的
+	}{
+		{board.Empty, 2, []board.Color{board.Black, board.White, board.Black, board.White}},
+		{board.Black, 2, []board.Color{board.Black, board.Black, board.Black, board.White, board.Black}},
+		{board.White, 1, []board.Color{board.Black, board.White, board.White, board.Black, board.White}},
+	}
+	for _, tt := range tests {
+		g := NewGame(board.Freestyle)
+		g.Start(tt.handicapColor, tt.handicap)
+		var got []board.Color
+		for i := range tt.want {
+			got = append(got, g.WhoseTurn())
+			if err := g.Play(pt(i*2, 7)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("handicap %d for %s: turns %v, want %v", tt.handicap, tt.handicapColor, got, tt.want)
+		}
+		// 悔掉全部着法之后让子数也要恢复，重新下出同样的顺序
+		for range tt.want {
+			if err := g.Undo(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got = got[:0]
+		for i := range tt.want {
+			got = append(got, g.WhoseTurn())
+			if err := g.Play(pt(i*2, 7)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("handicap %d for %s after undo: turns %v, want %v", tt.handicap, tt.handicapColor, got, tt.want)
+		}
+	}
+}
+
+// 把收到的事件记下来，tag用来区分多个观察者
+type eventLog struct {
+	tag    string
+	events *[]string
+}
+
+func (l eventLog) Notify(e Event) {
+	*l.events = append(*l.events, l.tag+":"+reflect.TypeOf(e).Name())
+}
+
+func TestObserverOrder(t *testing.T) {
+	var events []string
+	g := NewGame(board.Freestyle)
+	g.Subscribe(eventLog{"a", &events})
+	cancel := g.Subscribe(eventLog{"b", &events})
+	g.Start(board.Empty, 0)
+	for _, p := range []board.Point{pt(0, 0), pt(0, 1), pt(1, 0), pt(1, 1), pt(2, 0), pt(2, 1), pt(3, 0), pt(3, 1)} {
+		if err := g.Play(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events = events[:0]
+	_ = g.Play(pt(3, 0)) // 已有棋子
+	cancel()
+	_ = g.Play(pt(4, 0))
+	want := []string{
+		"a:MoveRejected", "b:MoveRejected",
+		"a:MovePlayed", "a:GameOver", // 取消订阅之后b不再收到
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	g := NewGame(board.Freestyle)
+	g.Start(board.White, 1)
+	moves := []board.Point{pt(7, 7), pt(8, 8), pt(9, 9), pt(6, 6)}
+	for _, p := range moves {
+		if err := g.Play(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.Forfeit("resign")
+	var got []Event
+	g.Replay(ObserverFunc(func(e Event) { got = append(got, e) }))
+	want := []Event{
+		GameStarted{Rule: board.Freestyle, Size: board.Size, HandicapColor: board.White, Handicap: 1},
+		MovePlayed{Color: board.Black, P: moves[0], Number: 1},
+		MovePlayed{Color: board.White, P: moves[1], Number: 2},
+		MovePlayed{Color: board.White, P: moves[2], Number: 3},
+		MovePlayed{Color: board.Black, P: moves[3], Number: 4},
+		GameOver{Winner: board.Black, Reason: "resign"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Replay sent %v, want %v", got, want)
+	}
+}
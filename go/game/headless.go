@@ -0,0 +1,47 @@
+package game
+
+import (
+	"errors"
+	"github.com/CuteReimu/gobang/board"
+)
+
+// RunHeadless 不显示界面，让两个玩家（黑先）从opening开始下一局棋，
+// 返回胜者（和棋返回board.Empty）和包括开局在内的全部着法。走了不合法的棋或者在同一步里重复提和的一方判负
+func RunHeadless(players []Player, opening []board.Point, rule board.Rule) (board.Color, []board.Point, error) {
+	g := NewGame(rule)
+	byColor := make(map[board.Color]Player)
+	for _, pl := range players {
+		byColor[pl.Color()] = pl
+		g.Subscribe(pl)
+	}
+	g.Start(board.Empty, 0)
+	for _, p := range opening {
+		if err := g.Play(p); err != nil {
+			return board.Empty, nil, err
+		}
+	}
+	offered := false
+	for {
+		if over, winner := g.Result(); over {
+			return winner, g.Moves(), nil
+		}
+		color := g.WhoseTurn()
+		p, err := byColor[color].Play()
+		if errors.Is(err, ErrDrawOffer) && offered {
+			g.Forfeit("illegal move")
+			continue
+		}
+		if errors.Is(err, ErrResign) || errors.Is(err, ErrDrawOffer) {
+			offered = errors.Is(err, ErrDrawOffer)
+			handlePlayError(g, color, err, byColor[color.Conversion()])
+			continue
+		}
+		if err != nil {
+			return board.Empty, g.Moves(), err
+		}
+		if g.Play(p) != nil {
+			g.Forfeit("illegal move")
+		}
+		offered = false
+	}
+}
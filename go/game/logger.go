@@ -0,0 +1,43 @@
+package game
+
+import (
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"io"
+	"time"
+)
+
+// Logger 把对局过程按行写到w，思考期间每秒一次的ClockTick不写
+type Logger struct {
+	w io.Writer
+}
+
+// NewLogger 创建写到w的Logger
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{w}
+}
+
+func (l *Logger) Notify(e Event) {
+	switch e := e.(type) {
+	case GameStarted:
+		fmt.Fprintf(l.w, "new game: %s, size %d\n", e.Rule, e.Size)
+	case MovePlayed:
+		fmt.Fprintf(l.w, "%s%s\n", e.Color, e.P)
+	case MoveUndone:
+		fmt.Fprintf(l.w, "undo %s%s\n", e.Color, e.P)
+	case MoveRejected:
+		fmt.Fprintf(l.w, "illegal move by %s: %s\n", e.Color, e.Err)
+	case DrawOffered:
+		answer := "declined"
+		if e.Accepted {
+			answer = "accepted"
+		}
+		fmt.Fprintf(l.w, "%s offers a draw: %s\n", e.Color, answer)
+	case GameOver:
+		fmt.Fprintf(l.w, "winner: %s (%s)\n", e.Winner, e.Reason)
+	case ClockTick:
+		if e.AfterMove {
+			fmt.Fprintf(l.w, "clock: %s %v, %s %v\n", board.Black, e.Black.Round(time.Second), board.White, e.White.Round(time.Second))
+		}
+	}
+}
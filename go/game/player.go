@@ -0,0 +1,27 @@
+package game
+
+import (
+	"errors"
+	"github.com/CuteReimu/gobang/board"
+)
+
+// Player 是对局的一方，Play返回自己的着法，也可以返回ErrResign认输或者返回ErrDrawOffer提和。
+// 玩家同时也是观察者，双方的着法都通过MovePlayed通知，自己的着法也要等收到通知之后才算下了
+type Player interface {
+	Observer
+	Color() board.Color
+	Play() (board.Point, error)
+}
+
+// Play返回这些错误表示不落子，而是认输、向对方提和，或者局面已经被别处改变（例如读取了棋谱）、超时被打断。
+// 提和被拒绝或者局面被改变之后，Run会重新看轮到谁下
+var (
+	ErrResign      = errors.New("resign")
+	ErrDrawOffer   = errors.New("draw offer")
+	ErrInterrupted = errors.New("interrupted")
+)
+
+// DrawResponder 是能回应提和的玩家，没有实现它的玩家总是拒绝
+type DrawResponder interface {
+	AcceptDraw() bool
+}
@@ -0,0 +1,203 @@
+package game
+
+import (
+	"errors"
+	"github.com/CuteReimu/gobang/board"
+	"io"
+	"log"
+	"time"
+)
+
+// Options 是Run的对局设置
+type Options struct {
+	Time        time.Duration // 每一方的总思考时间，0表示不限时
+	Increment   time.Duration // 每走一步增加的时间
+	Handicapped Player        // 被让子的玩家，开局可以连续下Handicap个子
+	Handicap    int
+	Retries     int                            // 走了不合法的棋之后还能重走几次，用完之后判负，0表示直接判负
+	AfterMove   func(pl Player, p board.Point) // 每走一步之后调用，可以为nil
+	Setup       func(g *Game)                  // 代替g.Start开始对局，可以摆出保存过的棋谱接着下，为nil时按让子设置清空棋盘
+}
+
+// ClockAware 是有时间限制时需要知道剩余时间的玩家，每次Play之前调用SetClock
+type ClockAware interface {
+	SetClock(remaining, increment time.Duration)
+}
+
+// Interrupter 是能在Play中途被打断的玩家。超时时Run调用Interrupt，Play应该尽快返回，返回的着法不再算数。
+// 没有在Play中时调用Interrupt什么都不做，Run会每隔一段时间再调用一次，直到Play返回
+type Interrupter interface {
+	Interrupt()
+}
+
+// Run 让players在g上从头下一局，返回胜者，和棋返回board.Empty，结束的原因可以用g.Reason查询。
+// 对局期间players会订阅g的事件，其它观察者可以自己调用g.Subscribe。
+// 玩家走了不合法的棋或者在同一步里重复提和时，先用g.Replay让它的棋盘和对局重新同步再让它重走，超过opts.Retries次判负。
+// 有时间限制时，思考超过剩余时间就判负，不等Play返回：实现了Interrupter的玩家会被打断，等它返回之后再判负；
+// 没有实现的玩家就不管它了，它的Play可能在收到GameOver之后才返回
+func Run(g *Game, players []Player, opts Options) board.Color {
+	byColor := make(map[board.Color]Player)
+	for _, pl := range players {
+		byColor[pl.Color()] = pl
+		defer g.Subscribe(pl)()
+	}
+	if opts.Setup != nil {
+		opts.Setup(g)
+	} else {
+		handicapColor := board.Empty
+		if opts.Handicapped != nil {
+			handicapColor = opts.Handicapped.Color()
+		}
+		g.Start(handicapColor, opts.Handicap)
+	}
+	clocks := map[board.Color]time.Duration{board.Black: opts.Time, board.White: opts.Time}
+	illegal, offered := 0, false
+	reject := func(current Player) {
+		if illegal++; illegal > opts.Retries {
+			g.Forfeit("illegal move")
+		} else {
+			g.Replay(current)
+		}
+	}
+	for {
+		if over, winner := g.Result(); over {
+			return winner
+		}
+		color := g.WhoseTurn()
+		current := byColor[color]
+		if c, ok := current.(ClockAware); ok && opts.Time > 0 {
+			c.SetClock(clocks[color], opts.Increment)
+		}
+		start := time.Now()
+		var stopTicking func()
+		if opts.Time > 0 {
+			stopTicking = tick(g, color, clocks, start)
+		}
+		p, err, timedOut := playWithin(current, clocks[color])
+		if stopTicking != nil {
+			stopTicking()
+		}
+		if opts.Time > 0 {
+			clocks[color] -= time.Since(start)
+			if timedOut || clocks[color] <= 0 {
+				clocks[color] = 0
+				g.Forfeit("time")
+				continue
+			}
+		}
+		if errors.Is(err, ErrDrawOffer) {
+			if offered { // 每一步只能提和一次，再提当作不合法的着法
+				reject(current)
+				continue
+			}
+			offered = true
+		}
+		if err != nil {
+			handlePlayError(g, color, err, byColor[color.Conversion()])
+			continue
+		}
+		if err := g.Play(p); err != nil {
+			reject(current)
+			continue
+		}
+		illegal, offered = 0, false
+		if opts.Time > 0 {
+			clocks[color] += opts.Increment
+			g.publish(ClockTick{Turn: g.WhoseTurn(), Black: clocks[board.Black], White: clocks[board.White], AfterMove: true})
+		}
+		if opts.AfterMove != nil {
+			opts.AfterMove(current, p)
+		}
+	}
+}
+
+// 处理color方Play返回的错误：退出或认输判负，提和时询问对方，局面被改变时什么都不用做，其它错误只记录下来，之后再让这一方重新Play
+func handlePlayError(g *Game, color board.Color, err error, opponent Player) {
+	switch {
+	case err == io.EOF:
+		g.Forfeit("quit")
+	case errors.Is(err, ErrResign):
+		g.Forfeit("resign")
+	case errors.Is(err, ErrInterrupted):
+	case errors.Is(err, ErrDrawOffer):
+		r, ok := opponent.(DrawResponder)
+		accepted := ok && r.AcceptDraw()
+		g.publish(DrawOffered{Color: color, Accepted: accepted})
+		if accepted {
+			g.Draw("agreement")
+		}
+	default:
+		log.Println(err.Error())
+	}
+}
+
+// 超时之后再次打断玩家的间隔，见Interrupter
+const interruptInterval = 100 * time.Millisecond
+
+// 让pl下一步，limit大于0时限时。超时时打断实现了Interrupter的pl并等它返回，返回的timedOut为true
+func playWithin(pl Player, limit time.Duration) (p board.Point, err error, timedOut bool) {
+	if limit <= 0 {
+		p, err = pl.Play()
+		return p, err, false
+	}
+	type result struct {
+		p   board.Point
+		err error
+	}
+	done := make(chan result, 1) // 不等的时候Play返回了也不会卡住
+	go func() {
+		p, err := pl.Play()
+		done <- result{p, err}
+	}()
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.p, r.err, false
+	case <-timer.C:
+	}
+	i, ok := pl.(Interrupter)
+	if !ok {
+		return board.Point{}, nil, true
+	}
+	ticker := time.NewTicker(interruptInterval)
+	defer ticker.Stop()
+	for {
+		i.Interrupt()
+		select {
+		case <-done:
+			return board.Point{}, nil, true
+		case <-ticker.C:
+		}
+	}
+}
+
+// 在color方思考期间每秒发出一次ClockTick，剩余时间最少是0，返回的函数停止发送并等待发送的goroutine退出
+func tick(g *Game, color board.Color, clocks map[board.Color]time.Duration, start time.Time) func() {
+	black, white := clocks[board.Black], clocks[board.White]
+	ticker := time.NewTicker(time.Second)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				e := ClockTick{Turn: color, Black: black, White: white}
+				if color == board.Black {
+					e.Black = max(e.Black-time.Since(start), 0)
+				} else {
+					e.White = max(e.White-time.Since(start), 0)
+				}
+				g.publish(e)
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-exited
+	}
+}
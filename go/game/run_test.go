@@ -0,0 +1,185 @@
+package game
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 按顺序返回script里的结果，用完之后一直等到interrupt里有值或者被关闭
+type scriptedPlayer struct {
+	color      board.Color
+	script     []any // board.Point或者error
+	interrupt  chan struct{}
+	acceptDraw bool
+	events     []Event
+}
+
+func newScriptedPlayer(color board.Color, script ...any) *scriptedPlayer {
+	return &scriptedPlayer{color: color, script: script, interrupt: make(chan struct{}, 1)}
+}
+
+func (s *scriptedPlayer) Color() board.Color {
+	return s.color
+}
+
+func (s *scriptedPlayer) Notify(e Event) {
+	s.events = append(s.events, e)
+}
+
+func (s *scriptedPlayer) AcceptDraw() bool {
+	return s.acceptDraw
+}
+
+func (s *scriptedPlayer) Play() (board.Point, error) {
+	if len(s.script) == 0 {
+		<-s.interrupt
+		return board.Point{}, ErrInterrupted
+	}
+	next := s.script[0]
+	s.script = s.script[1:]
+	if err, ok := next.(error); ok {
+		return board.Point{}, err
+	}
+	return next.(board.Point), nil
+}
+
+// 实现了Interrupter的scriptedPlayer，Run超时时会打断它
+type interruptiblePlayer struct {
+	*scriptedPlayer
+}
+
+func (p interruptiblePlayer) Interrupt() {
+	select {
+	case p.interrupt <- struct{}{}:
+	default:
+	}
+}
+
+func TestRunForfeitsOnTime(t *testing.T) {
+	tests := []struct {
+		name          string
+		interruptible bool
+	}{
+		{"interrupted", true},
+		{"abandoned", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			black := newScriptedPlayer(board.Black) // 一直不走
+			t.Cleanup(func() { close(black.interrupt) })
+			var pl Player = black
+			if tt.interruptible {
+				pl = interruptiblePlayer{black}
+			}
+			white := newScriptedPlayer(board.White)
+			g := NewGame(board.Freestyle)
+			done := make(chan board.Color)
+			go func() {
+				done <- Run(g, []Player{pl, white}, Options{Time: 50 * time.Millisecond})
+			}()
+			select {
+			case winner := <-done:
+				if winner != board.White || g.Reason() != "time" {
+					t.Errorf("Run = %s (%s), want white on time", winner, g.Reason())
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Run did not forfeit a player who never moves")
+			}
+		})
+	}
+}
+
+// 统计events里各种事件的个数，按类型名
+func countEvents(events []Event) map[string]int {
+	counts := make(map[string]int)
+	for _, e := range events {
+		counts[reflect.TypeOf(e).Name()]++
+	}
+	return counts
+}
+
+func TestRunEndings(t *testing.T) {
+	bad := board.Point{X: -1, Y: 0}
+	tests := []struct {
+		name       string
+		black      []any
+		white      []any
+		acceptDraw bool // 白方是否同意和棋
+		retries    int
+		winner     board.Color
+		reason     string
+		replays    int // 黑方因为重走而收到的GameStarted，不算开局那一次
+		draws      int // 发出的DrawOffered
+	}{
+		{"resign", []any{pt(7, 7), ErrResign}, []any{pt(8, 8)}, false, 0,
+			board.White, "resign", 0, 0},
+		{"illegal move without retries", []any{bad}, nil, false, 0,
+			board.White, "illegal move", 0, 0},
+		{"retries run out", []any{bad, bad}, nil, false, 1,
+			board.White, "illegal move", 1, 0},
+		{"a legal move resets the retries", []any{bad, pt(7, 7), bad, ErrResign}, []any{pt(8, 8)}, false, 1,
+			board.White, "resign", 2, 0},
+		{"draw accepted", []any{ErrDrawOffer}, nil, true, 0,
+			board.Empty, "agreement", 0, 1},
+		{"draw declined then resign", []any{ErrDrawOffer, ErrResign}, nil, false, 0,
+			board.White, "resign", 0, 1},
+		{"second draw offer in one turn is illegal", []any{ErrDrawOffer, ErrDrawOffer}, nil, false, 0,
+			board.White, "illegal move", 0, 1},
+		{"draw offers on different turns", []any{ErrDrawOffer, pt(7, 7), ErrDrawOffer, ErrResign}, []any{pt(8, 8)}, false, 0,
+			board.White, "resign", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			black := newScriptedPlayer(board.Black, tt.black...)
+			white := newScriptedPlayer(board.White, tt.white...)
+			white.acceptDraw = tt.acceptDraw
+			defer close(black.interrupt)
+			defer close(white.interrupt)
+			g := NewGame(board.Freestyle)
+			winner := Run(g, []Player{black, white}, Options{Retries: tt.retries})
+			if winner != tt.winner || g.Reason() != tt.reason {
+				t.Errorf("Run = %s (%s), want %s (%s)", winner, g.Reason(), tt.winner, tt.reason)
+			}
+			if len(black.script) > 0 {
+				t.Errorf("black still has %v to play", black.script)
+			}
+			counts := countEvents(black.events)
+			if counts["GameStarted"]-1 != tt.replays || counts["DrawOffered"] != tt.draws || counts["GameOver"] != 1 {
+				t.Errorf("black got %v, want %d replays and %d draw offers", counts, tt.replays, tt.draws)
+			}
+		})
+	}
+}
+
+func TestRunResyncsRejectedPlayer(t *testing.T) {
+	black := newScriptedPlayer(board.Black, pt(7, 7), pt(8, 8), pt(6, 6), ErrResign)
+	white := newScriptedPlayer(board.White, pt(8, 8), pt(9, 9))
+	defer close(black.interrupt)
+	defer close(white.interrupt)
+	g := NewGame(board.Freestyle)
+	Run(g, []Player{black, white}, Options{Retries: 1})
+	// 黑方把白方的8,8当成空点又下了一次，被拒绝之后要收到完整的对局重新同步，白方不受影响
+	var sinceReplay []Event
+	for _, e := range black.events {
+		if _, ok := e.(GameStarted); ok {
+			sinceReplay = nil
+		}
+		sinceReplay = append(sinceReplay, e)
+	}
+	want := []Event{
+		GameStarted{Rule: board.Freestyle, Size: board.Size},
+		MovePlayed{Color: board.Black, P: pt(7, 7), Number: 1},
+		MovePlayed{Color: board.White, P: pt(8, 8), Number: 2},
+		MovePlayed{Color: board.Black, P: pt(6, 6), Number: 3},
+		MovePlayed{Color: board.White, P: pt(9, 9), Number: 4},
+		GameOver{Winner: board.White, Reason: "resign"},
+	}
+	if !reflect.DeepEqual(sinceReplay, want) {
+		t.Errorf("black saw %v after the replay, want %v", sinceReplay, want)
+	}
+	if counts := countEvents(white.events); counts["GameStarted"] != 1 || counts["MoveRejected"] != 1 {
+		t.Errorf("white got %v, want one GameStarted and the MoveRejected", counts)
+	}
+}
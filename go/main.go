@@ -1,75 +1,89 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"github.com/hajimehoshi/ebiten/v2"
-	"log"
+	"github.com/CuteReimu/gobang/app"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/engine"
+	"github.com/CuteReimu/gobang/match"
+	"os"
+	"runtime"
+	"time"
 )
 
 func main() {
-	hp := newHumanPlayer(colorWhite)
-	//hp := newHumanWatcher()
-	go func() {
-		board := make([][]playerColor, maxLen)
-		for i := 0; i < maxLen; i++ {
-			board[i] = make([]playerColor, maxLen)
+	cfg := app.DefaultConfig()
+	cfg.RegisterFlags(flag.CommandLine)
+	configFile := flag.String("config", "", "JSON config file with the same keys as the flags above; flags override it")
+	buildBook := flag.String("buildbook", "", "build an opening book into this file and exit")
+	records := flag.String("records", "", "with -buildbook, game records to learn from, one game per line such as h8i9j10, or a record file")
+	selfPlay := flag.Int("selfplay", 0, "with -buildbook, number of self-play games to learn from")
+	bookPlies := flag.Int("bookplies", 10, "with -buildbook, number of moves of each game to put into the book")
+	matchGames := flag.Int("match", 0, "play this many headless games between -engine1 and -engine2 and exit")
+	engine1 := flag.String("engine1", "level=hard", "with -match, first engine, e.g. level=normal,depth=4,width=12,kill=8,blunder=0")
+	engine2 := flag.String("engine2", "level=hard", "with -match, second engine")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "with -match, number of games played in parallel")
+	elo0 := flag.Float64("elo0", 0, "with -match, SPRT null hypothesis elo difference")
+	elo1 := flag.Float64("elo1", 10, "with -match, SPRT alternative hypothesis elo difference")
+	alpha := flag.Float64("alpha", 0.05, "with -match, SPRT false positive rate")
+	beta := flag.Float64("beta", 0.05, "with -match, SPRT false negative rate")
+	tune := flag.Int("tune", 0, "tune evaluation weights with this many SPSA iterations of self-play based on -engine1, and exit")
+	tunePairs := flag.Int("tunepairs", 4, "with -tune, number of game pairs played in each iteration")
+	tuneOut := flag.String("tuneout", "weights.json", "with -tune, file the tuned weights are written to")
+	analyze := flag.String("analyze", "", "let the robot analyze every move of this record file (or a position such as 15;renju;w;h8j10;i9) and the final position, and exit; with -save, the scores are saved into a new record")
+	browse := flag.String("browse", "", "browse the opening tree of this record file (e.g. a Renlib .lib) in the terminal and exit")
+	export := flag.String("export", "", "draw the game loaded by -load into this .png (the final position) or .gif (every move) file and exit")
+	numbers := flag.Bool("numbers", false, "with -export, write move numbers on the stones")
+	lastMove := flag.Bool("lastmove", true, "with -export, mark the last move")
+	exportMove := flag.Int("exportmove", -1, "with -export, stop at this move instead of the end of the game")
+	delay := flag.Duration("delay", time.Second, "with -export to a .gif, time each move is shown")
+	replay := flag.Bool("replay", false, "review the game loaded by -load in the window instead of playing it; press B to play on from any move against the robot")
+	flag.Parse()
+	if *configFile != "" {
+		if err := cfg.ReadFile(*configFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
 		}
-		players := []player{newRobotPlayer(colorBlack), hp} // 机器人先
-		//players := []player{hp, newRobotPlayer(colorWhite)} // 玩家先
-		//players := []player{newRobotPlayer(colorBlack), newRobotPlayer(colorWhite)}
-		var watchers []*humanWatcher
-		//watchers = append(watchers, hp)
-		count := 0
-		whoseTurn := 0
-		checkForWin := func(p point) bool {
-			whose := board[p.y][p.x]
-			for _, dir := range fourDirections {
-				count := 0
-				for i := -4; i <= 4; i++ {
-					pi := p.move(dir, i)
-					if !pi.checkRange() || board[pi.y][pi.x] != whose {
-						count = 0
-					} else {
-						count++
-						if count == 5 {
-							return true
-						}
-					}
-				}
-			}
-			return false
-		}
-		for {
-			p, err := players[whoseTurn].play()
-			if err != nil {
-				log.Println(err.Error())
-				continue
-			}
-			if board[p.y][p.x] != 0 {
-				log.Printf("illegal argument: %s%s\n", p, board[p.y][p.x])
-				continue
-			}
-			board[p.y][p.x] = players[whoseTurn].color()
-			fmt.Printf("%s%s\n", board[p.y][p.x], p)
-			whoseTurn = 1 - whoseTurn
-			if err := players[whoseTurn].display(p); err != nil {
-				log.Println(err.Error())
-			}
-			for _, watcher := range watchers {
-				if err := watcher.display(board[p.y][p.x], p); err != nil {
-					log.Println(err.Error())
-				}
-			}
-			count++
-			if count == maxLen*maxLen || checkForWin(p) {
-				break
-			}
-		}
-		select {}
-	}()
-	ebiten.SetWindowSize(35*(maxLen+1), 35*(maxLen+1))
-	ebiten.SetWindowTitle("gobang")
-	if err := ebiten.RunGame(hp); err != nil {
-		panic(err)
+		flag.Parse() // 命令行参数优先于配置文件
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, "run with -h to see all options")
+		os.Exit(2)
+	}
+	board.Size = cfg.Size
+	var err error
+	switch {
+	case *tune > 0:
+		e1 := parseEngine("-engine1", *engine1)
+		err = cfg.Tune(e1, *tune, *tunePairs, *tuneOut)
+	case *matchGames > 0:
+		e1, e2 := parseEngine("-engine1", *engine1), parseEngine("-engine2", *engine2)
+		fmt.Println(cfg.Match(e1, e2, *matchGames, *concurrency, &match.SPRT{Elo0: *elo0, Elo1: *elo1, Alpha: *alpha, Beta: *beta}))
+	case *browse != "":
+		err = app.Browse(*browse, os.Stdin, os.Stdout)
+	case *export != "":
+		err = cfg.Export(*export, *numbers, *lastMove, *exportMove, *delay)
+	case *analyze != "":
+		err = cfg.Analyze(*analyze, os.Stdout)
+	case *buildBook != "":
+		err = cfg.BuildBook(*buildBook, *records, *selfPlay, *bookPlies)
+	default:
+		err = cfg.Play(*replay)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// 解析-engine1或-engine2，不合法时退出
+func parseEngine(name, s string) engine.Config {
+	cfg, err := engine.ParseConfig(s)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, name+":", err)
+		os.Exit(2)
 	}
+	return cfg
 }
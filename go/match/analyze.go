@@ -0,0 +1,55 @@
+package match
+
+import (
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/engine"
+	"github.com/CuteReimu/gobang/game"
+	"github.com/CuteReimu/gobang/record"
+	"io"
+)
+
+// Analyze 让cfg配置的机器人在棋谱rec的每一步之前分析局面，把评分和最佳变例写到w，评分同时记进rec每一步的Eval，
+// 对局没有结束时最后再分析轮到的一方该怎么下。棋盘大小必须已经设置成rec.Size
+func Analyze(rec *record.Record, cfg engine.Config, w io.Writer) error {
+	if rec.Size != board.Size {
+		return fmt.Errorf("record is for size %d, but the board is %d", rec.Size, board.Size)
+	}
+	g := game.NewGame(rec.Rule)
+	robots := map[board.Color]*engine.Robot{board.Black: cfg.NewPlayer(board.Black), board.White: cfg.NewPlayer(board.White)}
+	for _, r := range robots {
+		g.Subscribe(r)
+	}
+	g.Start(rec.HandicapColor, rec.Handicap)
+	for i := range rec.Moves {
+		m := &rec.Moves[i]
+		if turn := g.WhoseTurn(); m.Color != turn {
+			return fmt.Errorf("move %d: %s%s, but it is %s's turn", i+1, m.Color, m.P, turn)
+		}
+		a, err := robots[m.Color].Analyze(1)
+		if err != nil {
+			return fmt.Errorf("move %d: %w", i+1, err)
+		}
+		best := a.Lines[0]
+		m.Eval = &best.Value
+		if best.PV[0] == m.P {
+			fmt.Fprintf(w, "%d. %s%s %s\n", i+1, m.Color, m.P, best)
+		} else {
+			fmt.Fprintf(w, "%d. %s%s, robot prefers %s\n", i+1, m.Color, m.P, best)
+		}
+		if err := g.Play(m.P); err != nil {
+			return fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+	if over, winner := g.Result(); over {
+		fmt.Fprintf(w, "winner: %s (%s)\n", winner, g.Reason())
+		return nil
+	}
+	turn := g.WhoseTurn()
+	a, err := robots[turn].Analyze(1)
+	if err != nil {
+		return fmt.Errorf("%s to move: %w", turn, err)
+	}
+	fmt.Fprintf(w, "%d. %s to move, robot prefers %s\n", len(rec.Moves)+1, turn, a.Lines[0])
+	return nil
+}
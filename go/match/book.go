@@ -0,0 +1,110 @@
+package match
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/engine"
+	"github.com/CuteReimu/gobang/game"
+	"github.com/CuteReimu/gobang/record"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var movePattern = regexp.MustCompile(`(-?\d+)\s*,\s*(-?\d+)`)
+
+// 读取棋谱，每行一局，着法写作"x,y"或"(x,y)"，也可以像"h8i9j10"这样写，黑先交替落子
+func readMoveLists(r io.Reader) ([][]board.Point, error) {
+	var games [][]board.Point
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var moves []board.Point
+		for _, m := range movePattern.FindAllStringSubmatch(scanner.Text(), -1) {
+			x, _ := strconv.Atoi(m[1])
+			y, _ := strconv.Atoi(m[2])
+			moves = append(moves, board.Point{X: x, Y: y})
+		}
+		if len(moves) == 0 { // 不是着法的行和以前一样跳过
+			moves, _ = board.ParseMoves(strings.TrimSpace(scanner.Text()), board.Size)
+		}
+		if len(moves) > 0 {
+			games = append(games, moves)
+		}
+	}
+	return games, scanner.Err()
+}
+
+// 读取棋谱，record能识别的格式（例如RIF数据库和Renlib开局库）用record读取，其它文件按readMoveLists的格式读取。
+// 开局库按黑先交替落子收录着法，所以跳过规则或棋盘大小不同的棋谱和让子棋，返回跳过的局数
+func loadGames(name string, rule board.Rule) ([][]board.Point, int, error) {
+	if ext := strings.ToLower(filepath.Ext(name)); slices.Contains(record.Formats, ext) {
+		records, err := record.LoadAll(name)
+		if err != nil {
+			return nil, 0, err
+		}
+		var games [][]board.Point
+		for _, rec := range records {
+			if ext == ".psq" { // PSQ没有记录规则
+				rec.Rule = rule
+			}
+			if rec.Size == board.Size && rec.Rule == rule && rec.Handicap == 0 {
+				games = append(games, rec.Points())
+			}
+		}
+		return games, len(records) - len(games), nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	games, err := readMoveLists(f)
+	return games, 0, err
+}
+
+// BuildOpeningBook 用棋谱文件records和selfPlay局自我对弈生成开局库，写入out
+func BuildOpeningBook(out, records string, selfPlay, plies int, cfg engine.Config, rule board.Rule) error {
+	book := make(engine.OpeningBook)
+	if records != "" {
+		games, skipped, err := loadGames(records, rule)
+		if err != nil {
+			return err
+		}
+		for _, moves := range games {
+			book.AddGame(moves, plies, 1, board.Empty)
+		}
+		fmt.Printf("added %d games from %s\n", len(games), records)
+		if skipped > 0 {
+			fmt.Printf("skipped %d games with another rule, board size or a handicap\n", skipped)
+		}
+	}
+	// 同样的引擎下同样的开局总是得到同一局棋，所以和Run一样换着开局下，重复的对局不再收录
+	openings := makeOpenings(selfPlay, rand.New(rand.NewSource(time.Now().UnixNano())))
+	seen := make(map[string]bool)
+	added := 0
+	for i, opening := range openings {
+		players := []game.Player{cfg.NewPlayer(board.Black), cfg.NewPlayer(board.White)}
+		winner, moves, err := game.RunHeadless(players, opening, rule)
+		if err != nil {
+			return err
+		}
+		key := board.FormatMoves(moves, board.Size)
+		if winner != board.Empty && !seen[key] {
+			book.AddGame(moves, plies, 1, winner)
+			added++
+		}
+		fmt.Printf("self-play game %d/%d: %d moves, winner %s, duplicate %t\n", i+1, selfPlay, len(moves), winner, seen[key])
+		seen[key] = true
+	}
+	if selfPlay > 0 {
+		fmt.Printf("self-play: %d distinct games of %d, %d decisive ones added\n", len(seen), selfPlay, added)
+	}
+	return book.Save(out)
+}
@@ -0,0 +1,227 @@
+// Package match 用于评估和改进引擎：引擎之间的对弈测试、估值权重调参和生成开局库。
+package match
+
+import (
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/engine"
+	"github.com/CuteReimu/gobang/game"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 比较均衡的开局，黑先交替落子，每个开局双方各执黑一次
+var balancedOpenings = [][]board.Point{
+	{{X: 7, Y: 7}, {X: 7, Y: 6}, {X: 7, Y: 5}},
+	{{X: 7, Y: 7}, {X: 7, Y: 6}, {X: 8, Y: 5}},
+	{{X: 7, Y: 7}, {X: 7, Y: 6}, {X: 9, Y: 6}},
+	{{X: 7, Y: 7}, {X: 7, Y: 6}, {X: 6, Y: 8}},
+	{{X: 7, Y: 7}, {X: 8, Y: 6}, {X: 8, Y: 8}},
+	{{X: 7, Y: 7}, {X: 8, Y: 6}, {X: 9, Y: 7}},
+	{{X: 7, Y: 7}, {X: 8, Y: 6}, {X: 6, Y: 9}},
+	{{X: 7, Y: 7}, {X: 8, Y: 6}, {X: 5, Y: 7}},
+}
+
+// 开局是按15路棋盘写的，其它大小的棋盘需要平移到中心
+func centerOpening(opening []board.Point) []board.Point {
+	shift := board.Size/2 - 7
+	result := make([]board.Point, len(opening))
+	for i, p := range opening {
+		result[i] = board.Point{X: p.X + shift, Y: p.Y + shift}
+	}
+	return result
+}
+
+// 返回pairs对棋的开局。前几对依次用均衡开局，之后在均衡开局后面随机再下白黑各一子，尽量不和前面的重复，
+// 否则两个不会随机走棋的引擎只会把同样的几局反复下
+func makeOpenings(pairs int, rnd *rand.Rand) [][]board.Point {
+	openings := make([][]board.Point, pairs)
+	seen := make(map[string]bool)
+	for i := range openings {
+		opening := centerOpening(balancedOpenings[i%len(balancedOpenings)])
+		if i >= len(balancedOpenings) {
+			jittered := jitterOpening(opening, rnd)
+			for range 10 {
+				if !seen[board.FormatMoves(jittered, board.Size)] {
+					break
+				}
+				jittered = jitterOpening(opening, rnd)
+			}
+			opening = jittered
+		}
+		seen[board.FormatMoves(opening, board.Size)] = true
+		openings[i] = opening
+	}
+	return openings
+}
+
+// 在开局的棋子附近随机再下白黑各一子，轮到谁下不变。开局只有几个子，不会成五也不会是禁手
+func jitterOpening(opening []board.Point, rnd *rand.Rand) []board.Point {
+	result := append([]board.Point(nil), opening...)
+	for range 2 {
+		var near []board.Point
+		for y := 0; y < board.Size; y++ {
+			for x := 0; x < board.Size; x++ {
+				p := board.Point{X: x, Y: y}
+				taken, nearby := false, false
+				for _, q := range result {
+					taken = taken || q == p
+					nearby = nearby || max(abs(q.X-p.X), abs(q.Y-p.Y)) <= 2
+				}
+				if nearby && !taken {
+					near = append(near, p)
+				}
+			}
+		}
+		result = append(result, near[rnd.Intn(len(near))])
+	}
+	return result
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Result 是站在engine1的角度统计的对局结果
+type Result struct {
+	wins, draws, losses int
+}
+
+// Games 返回已经下完的局数
+func (m Result) Games() int {
+	return m.wins + m.draws + m.losses
+}
+
+// Score 返回每局得分的平均值和方差
+func (m Result) Score() (float64, float64) {
+	n := float64(m.Games())
+	if n == 0 {
+		return 0.5, 0
+	}
+	mean := (float64(m.wins) + float64(m.draws)/2) / n
+	variance := (float64(m.wins)*(1-mean)*(1-mean) + float64(m.draws)*(0.5-mean)*(0.5-mean) + float64(m.losses)*mean*mean) / n
+	return mean, variance
+}
+
+func eloFromScore(s float64) float64 {
+	s = math.Min(math.Max(s, 1e-6), 1-1e-6)
+	return -400 * math.Log10(1/s-1)
+}
+
+func scoreFromElo(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// 用于估计Elo和SPRT的平均得分、方差和局数，另外算上半局胜和半局负作为先验。
+// 否则全胜或全负时方差是0，置信区间变成±0，SPRT在证据最强的时候反而得不出结论
+func (m Result) estimate() (mean, variance, n float64) {
+	wins, draws, losses := float64(m.wins)+0.5, float64(m.draws), float64(m.losses)+0.5
+	n = wins + draws + losses
+	mean = (wins + draws/2) / n
+	variance = (wins*(1-mean)*(1-mean) + draws*(0.5-mean)*(0.5-mean) + losses*mean*mean) / n
+	return mean, variance, n
+}
+
+// Elo 返回Elo差和95%置信区间的半宽
+func (m Result) Elo() (float64, float64) {
+	if m.Games() == 0 {
+		return 0, math.Inf(1)
+	}
+	mean, variance, n := m.estimate()
+	delta := 1.96 * math.Sqrt(variance/n)
+	return eloFromScore(mean), (eloFromScore(mean+delta) - eloFromScore(mean-delta)) / 2
+}
+
+// LLR 返回H0: elo=elo0，H1: elo=elo1的对数似然比（正态近似）
+func (m Result) LLR(elo0, elo1 float64) float64 {
+	mean, variance, n := m.estimate()
+	s0, s1 := scoreFromElo(elo0), scoreFromElo(elo1)
+	return n * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+func (m Result) String() string {
+	elo, margin := m.Elo()
+	return fmt.Sprintf("games %d: +%d =%d -%d, elo %.1f +/- %.1f", m.Games(), m.wins, m.draws, m.losses, elo, margin)
+}
+
+// SPRT 是序贯概率比检验的参数
+type SPRT struct {
+	Elo0, Elo1, Alpha, Beta float64
+}
+
+// Verdict 返回对数似然比和结论，结论为空表示还需要继续下
+func (t SPRT) Verdict(m Result) (float64, string) {
+	llr := m.LLR(t.Elo0, t.Elo1)
+	lower := math.Log(t.Beta / (1 - t.Alpha))
+	upper := math.Log((1 - t.Beta) / t.Alpha)
+	switch {
+	case llr >= upper:
+		return llr, "H1 accepted"
+	case llr <= lower:
+		return llr, "H0 accepted"
+	}
+	return llr, ""
+}
+
+// Run 让两个引擎配置对弈最多games局，用concurrency个goroutine并行，test不为nil时SPRT得出结论后提前结束。
+// 每个开局双方各执黑下一局，均衡开局用完之后的开局是随机的，见makeOpenings
+func Run(engine1, engine2 engine.Config, games, concurrency int, test *SPRT, rule board.Rule) Result {
+	openings := makeOpenings((games+1)/2, rand.New(rand.NewSource(time.Now().UnixNano())))
+	var (
+		mu     sync.Mutex
+		result Result
+		done   atomic.Bool
+		next   atomic.Int64
+		wg     sync.WaitGroup
+	)
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done.Load() {
+				i := int(next.Add(1)) - 1
+				if i >= games {
+					return
+				}
+				opening := openings[i/2]
+				engine1Color := board.Black
+				players := []game.Player{engine1.NewPlayer(board.Black), engine2.NewPlayer(board.White)}
+				if i%2 == 1 {
+					engine1Color = board.White
+					players = []game.Player{engine2.NewPlayer(board.Black), engine1.NewPlayer(board.White)}
+				}
+				winner, moves, err := game.RunHeadless(players, opening, rule)
+				mu.Lock()
+				switch {
+				case err != nil:
+					fmt.Printf("game %d: %s\n", i+1, err)
+				case winner == board.Empty:
+					result.draws++
+				case winner == engine1Color:
+					result.wins++
+				default:
+					result.losses++
+				}
+				if test == nil {
+					fmt.Printf("game %d: %d moves, winner %s; %s\n", i+1, len(moves), winner, result)
+				} else {
+					llr, v := test.Verdict(result)
+					fmt.Printf("game %d: %d moves, winner %s; %s, llr %.2f\n", i+1, len(moves), winner, result, llr)
+					if v != "" && !done.Load() {
+						fmt.Printf("SPRT(%g, %g): %s\n", test.Elo0, test.Elo1, v)
+						done.Store(true)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return result
+}
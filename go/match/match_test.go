@@ -0,0 +1,65 @@
+package match
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSPRTOneSided(t *testing.T) {
+	test := SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 0.05}
+	tests := []struct {
+		result Result
+		want   string
+	}{
+		{Result{}, ""},
+		{Result{wins: 1, losses: 1}, ""},
+		{Result{wins: 6}, ""},
+		{Result{wins: 40}, "H1 accepted"},
+		{Result{losses: 40}, "H0 accepted"},
+		{Result{draws: 40}, ""},
+		{Result{wins: 400, draws: 200, losses: 300}, "H1 accepted"},
+		{Result{wins: 300, draws: 200, losses: 400}, "H0 accepted"},
+	}
+	for _, tt := range tests {
+		llr, verdict := test.Verdict(tt.result)
+		if verdict != tt.want || math.IsNaN(llr) {
+			t.Errorf("Verdict(%s) = %.2f %q, want %q", tt.result, llr, verdict, tt.want)
+		}
+	}
+}
+
+func TestSPRTAllWinsCrossesUpperBound(t *testing.T) {
+	test := SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 0.05}
+	upper := math.Log((1 - test.Beta) / test.Alpha)
+	prev := 0.0
+	for wins := 1; wins <= 100; wins++ {
+		llr, verdict := test.Verdict(Result{wins: wins})
+		if llr <= prev {
+			t.Fatalf("%d wins: llr %.3f did not grow from %.3f", wins, llr, prev)
+		}
+		prev = llr
+		if verdict != "" {
+			if verdict != "H1 accepted" || llr < upper {
+				t.Fatalf("%d wins: llr %.3f verdict %q, want H1 at %.3f", wins, llr, verdict, upper)
+			}
+			return
+		}
+	}
+	t.Fatalf("100 straight wins never crossed the upper bound %.3f, llr %.3f", upper, prev)
+}
+
+func TestEloMargin(t *testing.T) {
+	tests := []Result{{wins: 6}, {losses: 6}, {draws: 6}, {wins: 3, losses: 3}}
+	for _, r := range tests {
+		elo, margin := r.Elo()
+		if math.IsNaN(elo) || math.IsInf(elo, 0) || !(margin > 0) {
+			t.Errorf("Elo(%s) = %.1f +/- %.1f, want a finite elo and a positive margin", r, elo, margin)
+		}
+	}
+	if elo, _ := (Result{wins: 6}).Elo(); elo <= 0 {
+		t.Errorf("six wins give elo %.1f, want it positive", elo)
+	}
+	if _, margin := (Result{}).Elo(); !math.IsInf(margin, 1) {
+		t.Errorf("no games give margin %.1f, want +Inf", margin)
+	}
+}
@@ -0,0 +1,54 @@
+package match
+
+import (
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/engine"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Tune 用SPSA调整估值权重：每轮给每个权重各自随机选一个方向，按相同的幅度向上或向下扰动，得到两组权重，
+// 让它们互相对弈，再按胜负把权重往赢的一方移动，每轮结束后把结果写入out。
+// 权重以相对start的比例表示，所以大小相差很多的权重可以用同一个步长。BoardFive不参与调参，见EvalWeights.Params
+func Tune(base engine.Config, start *engine.EvalWeights, iterations, pairs int, out string, rule board.Rule) error {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	origin := start.Params()
+	theta := make([]float64, len(origin))
+	const a, c, A = 0.05, 0.1, 10.0
+	for k := 1; k <= iterations; k++ {
+		ak := a / math.Pow(float64(k)+A, 0.602)
+		ck := c / math.Pow(float64(k), 0.101)
+		delta := make([]float64, len(theta))
+		plus, minus := make([]float64, len(theta)), make([]float64, len(theta))
+		for i := range theta {
+			delta[i] = float64(rnd.Intn(2)*2 - 1)
+			plus[i] = theta[i] + ck*delta[i]
+			minus[i] = theta[i] - ck*delta[i]
+		}
+		e1, e2 := base, base
+		e1.Weights = weightsFromTheta(start, origin, plus)
+		e2.Weights = weightsFromTheta(start, origin, minus)
+		result := Run(e1, e2, pairs*2, pairs*2, nil, rule)
+		mean, _ := result.Score()
+		for i := range theta {
+			theta[i] += ak * (mean - 0.5) * 2 / (2 * ck * delta[i])
+			theta[i] = math.Max(theta[i], -0.9) // 不让权重变号
+		}
+		fmt.Printf("iteration %d/%d: %s\n", k, iterations, result)
+		if err := weightsFromTheta(start, origin, theta).Save(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 从start复制一组权重，参与调参的权重按theta缩放
+func weightsFromTheta(start *engine.EvalWeights, origin []*int, theta []float64) *engine.EvalWeights {
+	w := *start
+	for i, p := range w.Params() {
+		*p = int(math.Round(float64(*origin[i]) * (1 + theta[i])))
+	}
+	return &w
+}
@@ -0,0 +1,60 @@
+package record
+
+import (
+	"encoding/json"
+	"github.com/CuteReimu/gobang/board"
+	"io"
+	"strings"
+)
+
+// JSON中的着法坐标写作"h8"，和棋盘大小有关，所以在整个棋谱这一层转换
+type jsonRecord struct {
+	*recordFields
+	Moves []jsonMove `json:"moves"`
+}
+
+type recordFields Record
+
+type jsonMove struct {
+	Color board.Color `json:"color"`
+	P     string      `json:"p"`
+	Time  Duration    `json:"time,omitempty"`
+	Eval  *int        `json:"eval,omitempty"`
+}
+
+// ReadJSON 读取JSON格式的棋谱，字段和Record的json标签一致，坐标写作"h8"，也可以是以前的"x,y"
+func ReadJSON(r io.Reader) (*Record, error) {
+	rec := &Record{}
+	jr := jsonRecord{recordFields: (*recordFields)(rec)}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&jr); err != nil {
+		return nil, err
+	}
+	rec.Moves = make([]Move, len(jr.Moves))
+	for i, m := range jr.Moves {
+		var p board.Point
+		var err error
+		if strings.Contains(m.P, ",") {
+			err = p.UnmarshalText([]byte(m.P))
+		} else {
+			p, err = board.ParseNotation(m.P, rec.Size)
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec.Moves[i] = Move{Color: m.Color, P: p, Time: m.Time, Eval: m.Eval}
+	}
+	return rec, nil
+}
+
+// WriteJSON 把棋谱写成缩进的JSON
+func (r *Record) WriteJSON(w io.Writer) error {
+	jr := jsonRecord{recordFields: (*recordFields)(r), Moves: make([]jsonMove, len(r.Moves))}
+	for i, m := range r.Moves {
+		jr.Moves[i] = jsonMove{Color: m.Color, P: m.P.Notation(r.Size), Time: m.Time, Eval: m.Eval}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jr)
+}
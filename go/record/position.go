@@ -0,0 +1,49 @@
+package record
+
+import (
+	"errors"
+	"github.com/CuteReimu/gobang/board"
+)
+
+// FromPosition 把局面变成棋谱：黑白交替摆出双方的棋子，多出来的子算作让子，这样Restore之后轮到pos.Turn一方。
+// 交替摆子的过程中提前连成五时Restore会出错
+func FromPosition(pos *board.Position) (*Record, error) {
+	rec := &Record{Rule: pos.Rule, Size: pos.Size}
+	black, white := pos.Black, pos.White
+	extra := len(black) - len(white) // 轮到黑棋时双方一样多，轮到白棋时黑棋多一个
+	if pos.Turn == board.White {
+		extra--
+	}
+	switch {
+	case extra > 0:
+		rec.HandicapColor, rec.Handicap = board.Black, extra
+		rec.Moves = movesOf(board.Black, black[:extra])
+		black = black[extra:]
+	case extra < 0:
+		if len(black) == 0 {
+			return nil, errors.New("black must have a stone before white moves")
+		}
+		// 黑棋先下一步，白棋连下让子数加一步，只剩让子数个白子时说明轮到白棋下最后一步
+		rec.HandicapColor, rec.Handicap = board.White, -extra
+		n := min(-extra+1, len(white))
+		rec.Moves = append(movesOf(board.Black, black[:1]), movesOf(board.White, white[:n])...)
+		black, white = black[1:], white[n:]
+	}
+	for i := range max(len(black), len(white)) {
+		if i < len(black) {
+			rec.Moves = append(rec.Moves, Move{Color: board.Black, P: black[i]})
+		}
+		if i < len(white) {
+			rec.Moves = append(rec.Moves, Move{Color: board.White, P: white[i]})
+		}
+	}
+	return rec, nil
+}
+
+func movesOf(color board.Color, points []board.Point) []Move {
+	moves := make([]Move, len(points))
+	for i, p := range points {
+		moves[i] = Move{Color: color, P: p}
+	}
+	return moves
+}
@@ -0,0 +1,97 @@
+package record
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadPSQ 读取Piskvork的PSQ棋谱，Gomocup的对局就是这种格式。
+// 第一行是"Piskvork 15x15, 11:11, 0"，之后每行一步"x,y,用时毫秒"，坐标从1开始，黑先交替落子，
+// 着法后面的两行是双方引擎的名字。PSQ没有记录规则，返回的棋谱规则是board.Freestyle
+func ReadPSQ(r io.Reader) (*Record, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("empty psq file")
+	}
+	header := strings.TrimSpace(scanner.Text())
+	var width, height int
+	if _, err := fmt.Sscanf(header, "Piskvork %dx%d", &width, &height); err != nil {
+		return nil, fmt.Errorf("invalid psq header %q", header)
+	}
+	if width != height {
+		return nil, fmt.Errorf("psq board %dx%d is not square", width, height)
+	}
+	rec := &Record{Rule: board.Freestyle, Size: width}
+	var names []string
+	color := board.Black
+	for line := 2; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		fields := strings.Split(text, ",")
+		if len(fields) != 3 {
+			// 着法之后是引擎的名字和一些数字，只要名字
+			if _, err := strconv.Atoi(text); err != nil {
+				names = append(names, text)
+			}
+			continue
+		}
+		if len(names) > 0 {
+			return nil, fmt.Errorf("line %d: move %q after the brain names", line, text)
+		}
+		var x, y, ms int
+		if _, err := fmt.Sscanf(text, "%d,%d,%d", &x, &y, &ms); err != nil {
+			return nil, fmt.Errorf("line %d: invalid move %q", line, text)
+		}
+		if x < 1 || x > width || y < 1 || y > width {
+			return nil, fmt.Errorf("line %d: move %q is out of the %dx%d board", line, text, width, width)
+		}
+		move := Move{Color: color, P: board.Point{X: x - 1, Y: y - 1}, Time: Duration(time.Duration(ms) * time.Millisecond)}
+		rec.Moves = append(rec.Moves, move)
+		color = color.Conversion()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(names) >= 2 {
+		rec.Black, rec.White = names[0], names[1]
+	}
+	return rec, nil
+}
+
+// WritePSQ 把棋谱写成PSQ格式，让子的棋谱不能写成PSQ
+func (r *Record) WritePSQ(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "Piskvork %dx%d, 11:11, 0\n", r.Size, r.Size)
+	color := board.Black
+	for i, m := range r.Moves {
+		if m.Color != color {
+			return fmt.Errorf("move %d: psq needs black and white to alternate", i+1)
+		}
+		fmt.Fprintf(bw, "%d,%d,%d\n", m.P.X+1, m.P.Y+1, time.Duration(m.Time).Milliseconds())
+		color = color.Conversion()
+	}
+	fmt.Fprintln(bw, psqName(r.Black))
+	fmt.Fprintln(bw, psqName(r.White))
+	fmt.Fprintln(bw, "-1")
+	return bw.Flush()
+}
+
+// 名字写在单独的一行，不能为空也不能像着法或者数字
+func psqName(name string) string {
+	name = strings.ReplaceAll(strings.TrimSpace(name), ",", " ")
+	if _, err := strconv.Atoi(name); err != nil && name != "" {
+		return name
+	}
+	return strings.TrimSpace("unknown " + name)
+}
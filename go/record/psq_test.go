@@ -0,0 +1,110 @@
+package record
+
+import (
+	"bytes"
+	"github.com/CuteReimu/gobang/board"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadPSQ(t *testing.T) {
+	tests := []struct {
+		name         string
+		psq          string
+		black, white string
+		moves        []Move
+		ok           bool
+	}{
+		{
+			name:  "names and footer",
+			psq:   "Piskvork 15x15, 11:11, 0\n8,8,0\n9,7,1500\n\nembryo\nrapfi 2024\n-1\n",
+			black: "embryo", white: "rapfi 2024",
+			moves: []Move{
+				{Color: board.Black, P: board.Point{X: 7, Y: 7}},
+				{Color: board.White, P: board.Point{X: 8, Y: 6}, Time: Duration(1500 * time.Millisecond)},
+			},
+			ok: true,
+		},
+		{
+			name: "corners",
+			psq:  "Piskvork 20x20, 11:11, 0\r\n1,1,0\r\n20,20,0\r\n",
+			moves: []Move{
+				{Color: board.Black, P: board.Point{X: 0, Y: 0}},
+				{Color: board.White, P: board.Point{X: 19, Y: 19}},
+			},
+			ok: true,
+		},
+		{name: "only header", psq: "Piskvork 15x15, 11:11, 0\n", ok: true},
+		{name: "one name", psq: "Piskvork 15x15, 11:11, 0\n8,8,0\nembryo\n", moves: []Move{{Color: board.Black, P: board.Point{X: 7, Y: 7}}}, ok: true},
+		{name: "empty", psq: ""},
+		{name: "bad header", psq: "Gomoku 15x15\n8,8,0\n"},
+		{name: "not square", psq: "Piskvork 15x20, 11:11, 0\n"},
+		{name: "out of board", psq: "Piskvork 15x15, 11:11, 0\n16,1,0\n"},
+		{name: "zero", psq: "Piskvork 15x15, 11:11, 0\n0,1,0\n"},
+		{name: "bad move", psq: "Piskvork 15x15, 11:11, 0\n8,x,0\n"},
+		{name: "move after names", psq: "Piskvork 15x15, 11:11, 0\n8,8,0\nembryo\nrapfi\n9,9,0\n"},
+	}
+	for _, tt := range tests {
+		rec, err := ReadPSQ(strings.NewReader(tt.psq))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: ReadPSQ error %v, want ok %t", tt.name, err, tt.ok)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		if rec.Black != tt.black || rec.White != tt.white || !reflect.DeepEqual(rec.Moves, tt.moves) || rec.Rule != board.Freestyle {
+			t.Errorf("%s: ReadPSQ = %q vs %q %v %v, want %q vs %q %v", tt.name, rec.Black, rec.White, rec.Moves, rec.Rule, tt.black, tt.white, tt.moves)
+		}
+	}
+}
+
+func TestPSQName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"embryo", "embryo"},
+		{" rapfi ", "rapfi"},
+		{"a,b", "a b"},
+		{"", "unknown"},
+		{"123", "unknown 123"},
+		{"-1", "unknown -1"},
+	}
+	for _, tt := range tests {
+		if got := psqName(tt.name); got != tt.want {
+			t.Errorf("psqName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPSQRoundTrip(t *testing.T) {
+	rec := &Record{
+		Black: "embryo", White: "123", Rule: board.Freestyle, Size: 15,
+		Moves: []Move{
+			{Color: board.Black, P: board.Point{X: 7, Y: 7}, Time: Duration(20 * time.Millisecond)},
+			{Color: board.White, P: board.Point{X: 0, Y: 14}},
+			{Color: board.Black, P: board.Point{X: 14, Y: 0}, Time: Duration(time.Second)},
+		},
+	}
+	var buf bytes.Buffer
+	if err := rec.WritePSQ(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadPSQ(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := *rec
+	want.White = "unknown 123"
+	if !reflect.DeepEqual(got, &want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+
+	rec.Moves = append(rec.Moves, Move{Color: board.Black, P: board.Point{X: 1, Y: 1}})
+	if err := rec.WritePSQ(&bytes.Buffer{}); err == nil {
+		t.Error("WritePSQ accepted two black moves in a row")
+	}
+}
@@ -0,0 +1,197 @@
+// Package record 是棋谱：一局棋的对局信息和着法，以及棋谱文件的读写。
+package record
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/game"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Move 是棋谱中的一步，Time是这一步的思考时间，Eval是下这一步的引擎给出的评分，都可以没有
+type Move struct {
+	Color board.Color `json:"color"`
+	P     board.Point `json:"p"`
+	Time  Duration    `json:"time,omitempty"`
+	Eval  *int        `json:"eval,omitempty"`
+}
+
+// Record 是一局棋的棋谱。Reason为空表示对局还没有结束
+type Record struct {
+	Black         string      `json:"black"` // 双方的名字
+	White         string      `json:"white"`
+	Event         string      `json:"event,omitempty"` // 比赛名称
+	Rule          board.Rule  `json:"rule"`
+	Size          int         `json:"size"`
+	Date          time.Time   `json:"date"`
+	Time          Duration    `json:"time,omitempty"` // 每一方的总思考时间，0表示不限时
+	Increment     Duration    `json:"increment,omitempty"`
+	HandicapColor board.Color `json:"handicap_color,omitempty"`
+	Handicap      int         `json:"handicap,omitempty"`
+	Opening       string      `json:"opening,omitempty"`      // 连珠的开局名称，例如花月
+	Alternatives  int         `json:"alternatives,omitempty"` // 连珠开局规则中黑棋提供的第5手打点数
+	Winner        board.Color `json:"winner,omitempty"`
+	Reason        string      `json:"reason,omitempty"`
+	Moves         []Move      `json:"moves"`
+}
+
+// Duration 是写成"1m30s"这种形式的time.Duration
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Points 按顺序返回全部着法的坐标
+func (r *Record) Points() []board.Point {
+	points := make([]board.Point, len(r.Moves))
+	for i, m := range r.Moves {
+		points[i] = m.P
+	}
+	return points
+}
+
+// Restore 在g上按棋谱的让子设置重新开始一局，并依次摆出全部着法，用于继续下保存过的对局。
+// 着法不合法或者和轮到的一方不一致时返回错误，此时g停在出错之前的局面
+func (r *Record) Restore(g *game.Game) error {
+	if r.Size != board.Size {
+		return fmt.Errorf("record is for size %d, but the board is %d", r.Size, board.Size)
+	}
+	g.Start(r.HandicapColor, r.Handicap)
+	for i, m := range r.Moves {
+		if turn := g.WhoseTurn(); m.Color != turn {
+			return fmt.Errorf("move %d: %s%s, but it is %s's turn", i+1, m.Color, m.P, turn)
+		}
+		if err := g.Play(m.P); err != nil {
+			return fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+	// 认输、超时、和棋以及数据库里记下的结果都不是由着法决定的，也要恢复
+	if over, _ := g.Result(); !over && r.Reason != "" {
+		g.End(r.Winner, r.Reason)
+	}
+	return nil
+}
+
+// Formats 是Load和LoadAll能识别的棋谱文件扩展名
+var Formats = []string{".json", ".psq", ".sgf", ".rif", ".xml", ".lib"}
+
+// Load 读取棋谱文件，按扩展名判断格式。文件中有很多局时返回第一局，有变化时返回主变
+func Load(name string) (*Record, error) {
+	records, err := LoadAll(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s: no game", name)
+	}
+	return records[0], nil
+}
+
+// LoadAll 读取棋谱文件中的全部对局，SGF棋谱和Renlib开局库中的每个变化都算一局
+func LoadAll(name string) ([]*Record, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var records []*Record
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".json", ".psq":
+		var r *Record
+		if ext == ".json" {
+			r, err = ReadJSON(f)
+		} else {
+			r, err = ReadPSQ(f)
+		}
+		records = []*Record{r}
+	case ".rif", ".xml":
+		records, err = ReadRIF(f)
+	case ".sgf", ".lib":
+		var trees []*Tree
+		if trees, err = readTrees(f, ext); err == nil {
+			for _, t := range trees {
+				records = append(records, t.Records()...)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%s: unknown record format %q", name, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return records, nil
+}
+
+// LoadTree 读取开局树，只有一局的SGF棋谱和Renlib开局库直接读成树，其它的把其中的全部对局合并成树
+func LoadTree(name string) (*Tree, error) {
+	if ext := strings.ToLower(filepath.Ext(name)); ext == ".sgf" || ext == ".lib" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		trees, err := readTrees(f, ext)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if len(trees) == 1 {
+			return trees[0], nil
+		}
+		var records []*Record
+		for _, t := range trees {
+			records = append(records, t.Records()...)
+		}
+		return NewTree(records), nil
+	}
+	records, err := LoadAll(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewTree(records), nil
+}
+
+func readTrees(r io.Reader, ext string) ([]*Tree, error) {
+	if ext == ".sgf" {
+		return ReadSGF(r)
+	}
+	t, err := ReadRenlib(r)
+	if err != nil {
+		return nil, err
+	}
+	return []*Tree{t}, nil
+}
+
+// Save 把棋谱写入文件，按扩展名判断格式
+func (r *Record) Save(name string) error {
+	var buf bytes.Buffer
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".json":
+		err = r.WriteJSON(&buf)
+	case ".psq":
+		err = r.WritePSQ(&buf)
+	case ".sgf":
+		err = r.WriteSGF(&buf)
+	default:
+		return fmt.Errorf("%s: unknown record format %q", name, ext)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), 0644)
+}
@@ -0,0 +1,89 @@
+package record
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/game"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Recorder 订阅对局事件并记下棋谱，每一步的用时是这一步和上一步（或开局）之间的时间
+type Recorder struct {
+	mu      sync.Mutex
+	rec     Record
+	old     []Move // Reset时的着法，重新摆出同样的着法时沿用原来的用时和评分
+	resumed bool   // Reset之后的第一局是在继续rec，保留rec的日期
+	last    time.Time
+}
+
+// NewRecorder 创建Recorder，rec中的双方名字、时间设置等对局信息会写进棋谱，着法从对局事件中得到
+func NewRecorder(rec Record) *Recorder {
+	r := &Recorder{}
+	r.Reset(rec)
+	return r
+}
+
+// Reset 换成rec的对局信息，之后如果按rec的着法重新摆棋，会保留其中的用时和评分
+func (r *Recorder) Reset(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.old = rec.Moves
+	r.resumed = true
+	rec.Moves = nil
+	r.rec = rec
+}
+
+func (r *Recorder) Notify(e game.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch e := e.(type) {
+	case game.GameStarted:
+		if !r.resumed {
+			r.old, r.rec.Date = nil, time.Time{}
+		}
+		r.resumed = false
+		r.rec.Rule, r.rec.Size = e.Rule, e.Size
+		r.rec.HandicapColor, r.rec.Handicap = e.HandicapColor, e.Handicap
+		r.rec.Winner, r.rec.Reason = board.Empty, ""
+		r.rec.Moves = nil
+		if r.rec.Date.IsZero() {
+			r.rec.Date = time.Now().Truncate(time.Second)
+		}
+		r.last = time.Now()
+	case game.MovePlayed:
+		m := Move{Color: e.Color, P: e.P, Time: Duration(time.Since(r.last).Round(time.Millisecond))}
+		if i := e.Number - 1; i < len(r.old) && r.old[i].Color == e.Color && r.old[i].P == e.P {
+			m = r.old[i]
+		} else {
+			r.old = nil
+		}
+		r.rec.Moves = append(r.rec.Moves, m)
+		r.last = time.Now()
+	case game.MoveUndone:
+		r.rec.Moves = r.rec.Moves[:max(len(r.rec.Moves)-1, 0)]
+		r.rec.Winner, r.rec.Reason = board.Empty, ""
+		r.old = nil
+		r.last = time.Now()
+	case game.GameOver:
+		r.rec.Winner, r.rec.Reason = e.Winner, e.Reason
+	}
+}
+
+// SetEval 记下最后一步的引擎评分
+func (r *Recorder) SetEval(eval int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.rec.Moves) > 0 {
+		r.rec.Moves[len(r.rec.Moves)-1].Eval = &eval
+	}
+}
+
+// Record 返回到目前为止的棋谱的副本
+func (r *Recorder) Record() *Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := r.rec
+	rec.Moves = slices.Clone(r.rec.Moves)
+	return &rec
+}
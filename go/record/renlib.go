@@ -0,0 +1,113 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"io"
+	"unicode/utf8"
+)
+
+// Renlib每个节点的标志位
+const (
+	renlibDown       = 0x80 // 下一个节点是它的第一个子节点
+	renlibRight      = 0x40 // 它的子树之后是它的下一个兄弟节点
+	renlibOldComment = 0x20 // 旧版本的注释，格式不同，不支持
+	renlibComment    = 0x08 // 后面跟着以0结尾的注释
+	renlibNoMove     = 0x02 // 节点没有着法，只用于表示空棋盘的根节点
+	renlibExtension  = 0x01 // 后面还有2个字节的扩展标志
+)
+
+// ReadRenlib 读取Renlib的.lib开局库。文件以20字节的文件头开始，前7个字节是0xFF和"RenLib"，
+// 之后按先序排列每个节点：1个字节的坐标（x是低4位减1，y是高4位，都从0开始）和1个字节的标志。
+// 黑先交替落子，规则是连珠，棋盘是15路
+func ReadRenlib(r io.Reader) (*Tree, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, 20)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("renlib header: %w", err)
+	}
+	if header[0] != 0xff || !bytes.Equal(header[1:7], []byte("RenLib")) {
+		return nil, errors.New("not a renlib file")
+	}
+	t := &Tree{Info: Record{Rule: board.Renju, Size: 15}, Root: &Node{}}
+	depth := map[*Node]int{t.Root: 0}
+	parent := t.Root
+	var stack []*Node // 还有兄弟节点没读到的那些节点的父节点
+	for first := true; ; first = false {
+		var rec [2]byte
+		if _, err := io.ReadFull(br, rec[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		pos, flags := rec[0], rec[1]
+		if flags&renlibOldComment != 0 {
+			return nil, errors.New("renlib: unsupported flag 0x20 (old-style comment), save the file with a newer RenLib")
+		}
+		if flags&renlibNoMove != 0 && !first {
+			return nil, errors.New("renlib: unsupported flag 0x02 (node without a move) below the root")
+		}
+		if flags&renlibExtension != 0 {
+			if _, err := br.Discard(2); err != nil {
+				return nil, err
+			}
+		}
+		node := t.Root // 第一个节点坐标为0或者没有着法时表示空棋盘，就是根节点
+		if !first || pos != 0 && flags&renlibNoMove == 0 {
+			p := board.Point{X: int(pos&0x0f) - 1, Y: int(pos >> 4)}
+			if p.X < 0 || p.X >= t.Info.Size || p.Y >= t.Info.Size {
+				return nil, fmt.Errorf("renlib: invalid move byte 0x%02x", pos)
+			}
+			color := board.Black
+			if depth[parent]%2 == 1 {
+				color = board.White
+			}
+			node = &Node{Move: &Move{Color: color, P: p}}
+			parent.Children = append(parent.Children, node)
+			depth[node] = depth[parent] + 1
+		}
+		if flags&renlibComment != 0 {
+			comment, err := readRenlibString(br)
+			if err != nil {
+				return nil, err
+			}
+			node.Comment = comment
+		}
+		if flags&renlibRight != 0 && node != t.Root {
+			stack = append(stack, parent)
+		}
+		if flags&renlibDown != 0 {
+			parent = node
+		} else if len(stack) == 0 {
+			break
+		} else {
+			parent, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		}
+	}
+	return t, nil
+}
+
+// 注释以0结尾，连同结尾的0补齐到偶数个字节
+func readRenlibString(br *bufio.Reader) (string, error) {
+	s, err := br.ReadBytes(0)
+	if err != nil {
+		return "", fmt.Errorf("renlib comment: %w", err)
+	}
+	if len(s)%2 == 1 {
+		if _, err := br.ReadByte(); err != nil {
+			return "", fmt.Errorf("renlib comment: %w", err)
+		}
+	}
+	s = bytes.TrimSpace(s[:len(s)-1])
+	if utf8.Valid(s) {
+		return string(s), nil
+	}
+	runes := make([]rune, len(s)) // 不是UTF-8时按Latin-1处理
+	for i, b := range s {
+		runes[i] = rune(b)
+	}
+	return string(runes), nil
+}
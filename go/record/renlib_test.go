@@ -0,0 +1,93 @@
+package record
+
+import (
+	"bytes"
+	"github.com/CuteReimu/gobang/board"
+	"strings"
+	"testing"
+)
+
+func renlibFile(nodes ...byte) []byte {
+	header := append([]byte{0xff}, "RenLib"...)
+	return append(append(header, make([]byte, 13)...), nodes...)
+}
+
+// 把树写成"h8(i9(j10) g9{hi})"的样子，方便比较子节点和兄弟节点的顺序
+func renlibShape(n *Node) string {
+	var sb strings.Builder
+	for i, c := range n.Children {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(c.Move.P.Notation(15))
+		if c.Move.Color == board.White {
+			sb.WriteString("w")
+		}
+		if c.Comment != "" {
+			sb.WriteString("{" + c.Comment + "}")
+		}
+		if len(c.Children) > 0 {
+			sb.WriteString("(" + renlibShape(c) + ")")
+		}
+	}
+	return sb.String()
+}
+
+func TestReadRenlib(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []byte
+		want  string
+		ok    bool
+	}{
+		{
+			name: "child and sibling",
+			// 根、h8，i9有兄弟节点，j10是i9的子节点，g9是i9的兄弟节点并且带注释
+			nodes: []byte{0x00, 0x80, 0x78, 0x80, 0x69, 0xc0, 0x5a, 0x00, 0x67, 0x08, 'h', 'i', 0, 0},
+			want:  "h8(i9w(j10) g9w{hi})",
+			ok:    true,
+		},
+		{
+			name:  "siblings at the root",
+			nodes: []byte{0x00, 0x80, 0x78, 0xc0, 0x69, 0x00, 0x79, 0x00},
+			want:  "h8(i9w) i8",
+			ok:    true,
+		},
+		{
+			name:  "first move without an empty root",
+			nodes: []byte{0x78, 0x80, 0x69, 0x00},
+			want:  "h8(i9w)",
+			ok:    true,
+		},
+		{
+			name:  "root without a move",
+			nodes: []byte{0x78, 0x82, 0x69, 0x00},
+			want:  "i9",
+			ok:    true,
+		},
+		{
+			name:  "even comment and extension",
+			nodes: []byte{0x00, 0x80, 0x78, 0x09, 0xaa, 0xbb, 'o', 'k', 'a', 0},
+			want:  "h8{oka}",
+			ok:    true,
+		},
+		{name: "old comment", nodes: []byte{0x00, 0x80, 0x78, 0x20}},
+		{name: "no move below the root", nodes: []byte{0x00, 0x80, 0x78, 0x82, 0x69, 0x02}},
+		{name: "bad move", nodes: []byte{0x00, 0x80, 0x70, 0x00}},
+		{name: "unterminated comment", nodes: []byte{0x00, 0x80, 0x78, 0x08, 'h', 'i'}},
+		{name: "truncated", nodes: []byte{0x00, 0x80, 0x78}},
+	}
+	for _, tt := range tests {
+		tree, err := ReadRenlib(bytes.NewReader(renlibFile(tt.nodes...)))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: ReadRenlib error %v, want ok %t", tt.name, err, tt.ok)
+			continue
+		}
+		if tt.ok && renlibShape(tree.Root) != tt.want {
+			t.Errorf("%s: ReadRenlib = %s, want %s", tt.name, renlibShape(tree.Root), tt.want)
+		}
+	}
+	if _, err := ReadRenlib(bytes.NewReader([]byte("not a renlib file at all"))); err == nil {
+		t.Error("ReadRenlib accepted a file without the header")
+	}
+}
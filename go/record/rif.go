@@ -0,0 +1,110 @@
+package record
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RIF数据库的XML格式，只取用得到的部分
+type rifDatabase struct {
+	Players     []rifPlayer `xml:"players>player"`
+	Tournaments []rifNamed  `xml:"tournaments>tournament"`
+	Openings    []rifNamed  `xml:"openings>opening"`
+	Rules       []rifNamed  `xml:"rules>rule"`
+	Games       []rifGame   `xml:"games>game"`
+}
+
+type rifPlayer struct {
+	ID      string `xml:"id,attr"`
+	Name    string `xml:"name,attr"`
+	Surname string `xml:"surname,attr"`
+}
+
+type rifNamed struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name,attr"`
+}
+
+type rifGame struct {
+	ID         string `xml:"id,attr"`
+	Tournament string `xml:"tournament,attr"`
+	Date       string `xml:"date,attr"`
+	Black      string `xml:"black,attr"`
+	White      string `xml:"white,attr"`
+	BResult    string `xml:"bresult,attr"` // 黑方的得分：1、0.5或0
+	Opening    string `xml:"opening,attr"`
+	Alt        string `xml:"alt,attr"` // 第5手的打点数
+	Rule       string `xml:"rule,attr"`
+	Moves      string `xml:"move"` // 例如"h8 i9 j10"，坐标是Point.Notation的写法
+}
+
+// ReadRIF 读取连珠国际联盟（RIF）发布的XML对局数据库，返回其中的全部对局。
+// 选手、比赛、开局和规则在数据库中分别列出，对局中用编号引用。没有记录怎样结束的对局，结束原因写作recorded
+func ReadRIF(r io.Reader) ([]*Record, error) {
+	var db rifDatabase
+	if err := xml.NewDecoder(r).Decode(&db); err != nil {
+		return nil, err
+	}
+	players := make(map[string]string)
+	for _, p := range db.Players {
+		players[p.ID] = strings.TrimSpace(p.Name + " " + p.Surname)
+	}
+	names := func(list []rifNamed) map[string]string {
+		m := make(map[string]string)
+		for _, n := range list {
+			m[n.ID] = n.Name
+		}
+		return m
+	}
+	tournaments, openings, rules := names(db.Tournaments), names(db.Openings), names(db.Rules)
+	var records []*Record
+	for _, g := range db.Games {
+		rec := &Record{
+			Black:   players[g.Black],
+			White:   players[g.White],
+			Event:   tournaments[g.Tournament],
+			Rule:    rifRule(rules[g.Rule]),
+			Size:    15,
+			Opening: openings[g.Opening],
+		}
+		rec.Alternatives, _ = strconv.Atoi(g.Alt)
+		if d, err := time.Parse("2006-01-02", g.Date); err == nil {
+			rec.Date = d
+		}
+		color := board.Black
+		for _, s := range strings.Fields(g.Moves) {
+			p, err := board.ParseNotation(s, rec.Size)
+			if err != nil {
+				return nil, fmt.Errorf("game %s: %w", g.ID, err)
+			}
+			rec.Moves = append(rec.Moves, Move{Color: color, P: p})
+			color = color.Conversion()
+		}
+		switch g.BResult {
+		case "1":
+			rec.Winner, rec.Reason = board.Black, "recorded"
+		case "0":
+			rec.Winner, rec.Reason = board.White, "recorded"
+		case "0.5":
+			rec.Winner, rec.Reason = board.Empty, "recorded"
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// RIF数据库中规则的名字，除了五子棋以外都按连珠处理
+func rifRule(name string) board.Rule {
+	switch name = strings.ToLower(name); {
+	case strings.Contains(name, "freestyle"):
+		return board.Freestyle
+	case strings.Contains(name, "gomoku"):
+		return board.Standard
+	}
+	return board.Renju
+}
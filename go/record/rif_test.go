@@ -0,0 +1,81 @@
+package record
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"strings"
+	"testing"
+	"time"
+)
+
+const rifSample = `<?xml version="1.0" encoding="utf-8"?>
+<database>
+  <players>
+    <player id="1" name="Ana" surname="Sokolova"/>
+    <player id="2" name="Oskar"/>
+  </players>
+  <tournaments><tournament id="7" name="World Championship"/></tournaments>
+  <openings><opening id="3" name="kagetsu"/></openings>
+  <rules>
+    <rule id="1" name="RIF"/>
+    <rule id="2" name="Gomoku"/>
+    <rule id="3" name="Freestyle gomoku"/>
+  </rules>
+  <games>
+    <game id="10" tournament="7" date="2019-08-02" black="1" white="2" bresult="1" opening="3" alt="2" rule="1"><move>h8 i9 j10</move></game>
+    <game id="11" black="2" white="1" bresult="0" rule="2"><move>a1 o15</move></game>
+    <game id="12" black="1" white="2" bresult="0.5" rule="3"><move></move></game>
+    <game id="13" black="1" white="2" rule="9"><move>h8</move></game>
+  </games>
+</database>`
+
+func TestReadRIF(t *testing.T) {
+	records, err := ReadRIF(strings.NewReader(rifSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		black, white, event, opening string
+		rule                         board.Rule
+		date                         time.Time
+		alt                          int
+		moves                        string
+		winner                       board.Color
+		reason                       string
+	}{
+		{"Ana Sokolova", "Oskar", "World Championship", "kagetsu", board.Renju, time.Date(2019, 8, 2, 0, 0, 0, 0, time.UTC), 2, "h8i9j10", board.Black, "recorded"},
+		{"Oskar", "Ana Sokolova", "", "", board.Standard, time.Time{}, 0, "a1o15", board.White, "recorded"},
+		{"Ana Sokolova", "Oskar", "", "", board.Freestyle, time.Time{}, 0, "", board.Empty, "recorded"},
+		{"Ana Sokolova", "Oskar", "", "", board.Renju, time.Time{}, 0, "h8", board.Empty, ""},
+	}
+	if len(records) != len(tests) {
+		t.Fatalf("ReadRIF read %d games, want %d", len(records), len(tests))
+	}
+	for i, tt := range tests {
+		rec := records[i]
+		var moves strings.Builder
+		for j, m := range rec.Moves {
+			if want := []board.Color{board.Black, board.White}[j%2]; m.Color != want {
+				t.Errorf("game %d move %d is %v, want %v", i, j+1, m.Color, want)
+			}
+			moves.WriteString(m.P.Notation(rec.Size))
+		}
+		if rec.Black != tt.black || rec.White != tt.white || rec.Event != tt.event || rec.Opening != tt.opening ||
+			rec.Rule != tt.rule || !rec.Date.Equal(tt.date) || rec.Alternatives != tt.alt || rec.Size != 15 ||
+			moves.String() != tt.moves || rec.Winner != tt.winner || rec.Reason != tt.reason {
+			t.Errorf("game %d = %+v, moves %s; want %+v", i, *rec, moves.String(), tt)
+		}
+	}
+}
+
+func TestReadRIFErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"<database><games><game id=\"1\"><move>h8 p1</move></game></games></database>",
+		"<database><games><game id=\"1\"><move>h8 x</move></game>",
+	}
+	for _, s := range tests {
+		if _, err := ReadRIF(strings.NewReader(s)); err == nil {
+			t.Errorf("ReadRIF(%q) succeeded, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,545 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SGF中记号的属性名，按这个顺序写出
+var sgfShapes = []string{"TR", "CR", "SQ", "MA", "LB"}
+
+// SGF中评价一步棋的属性，值为2表示程度更重
+var sgfAnnotations = map[string]string{"!": "TE[1]", "!!": "TE[2]", "?": "BM[1]", "??": "BM[2]", "!?": "IT[]", "?!": "DO[]"}
+
+// ReadSGF 读取SGF（FF[4]，GM[4]是五子棋和连珠）棋谱，一个文件里可以有多局，每局都带着变化、注释和评价。
+// SGF的坐标是两个字母，第一个是列，第二个是行，都从a开始，a是最上面一行，和board.Point一样。
+// 不支持摆子（AB、AW、AE）和停一手，其它不认识的属性保存在Node.Extra中
+func ReadSGF(r io.Reader) ([]*Tree, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &sgfParser{data: data}
+	var trees []*Tree
+	for {
+		i := bytes.IndexByte(p.data[p.i:], '(')
+		if i < 0 {
+			break
+		}
+		p.i += i
+		st, err := p.gameTree()
+		if err != nil {
+			return nil, err
+		}
+		t, err := newSGFTree(st)
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", len(trees)+1, err)
+		}
+		trees = append(trees, t)
+	}
+	if len(trees) == 0 {
+		return nil, errors.New("no game in sgf file")
+	}
+	return trees, nil
+}
+
+// 语法分析得到的一棵树：一串节点，之后是若干个变化
+type sgfTree struct {
+	nodes    []map[string][]string
+	children []*sgfTree
+}
+
+type sgfParser struct {
+	data []byte
+	i    int
+}
+
+func (p *sgfParser) skipSpace() {
+	for p.i < len(p.data) && strings.IndexByte(" \t\r\n", p.data[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// 从'('开始读到对应的')'
+func (p *sgfParser) gameTree() (*sgfTree, error) {
+	p.i++
+	t := &sgfTree{}
+	for {
+		p.skipSpace()
+		if p.i >= len(p.data) {
+			return nil, errors.New("sgf: unexpected end of file")
+		}
+		switch p.data[p.i] {
+		case ';':
+			if len(t.children) > 0 {
+				return nil, fmt.Errorf("sgf: node after variations at offset %d", p.i)
+			}
+			p.i++
+			props, err := p.properties()
+			if err != nil {
+				return nil, err
+			}
+			t.nodes = append(t.nodes, props)
+		case '(':
+			child, err := p.gameTree()
+			if err != nil {
+				return nil, err
+			}
+			t.children = append(t.children, child)
+		case ')':
+			p.i++
+			if len(t.nodes) == 0 {
+				return nil, fmt.Errorf("sgf: empty game tree at offset %d", p.i)
+			}
+			return t, nil
+		default:
+			return nil, fmt.Errorf("sgf: unexpected %q at offset %d", p.data[p.i], p.i)
+		}
+	}
+}
+
+// 读一个节点的全部属性。FF[3]的属性名里可以有小写字母，只取其中的大写字母
+func (p *sgfParser) properties() (map[string][]string, error) {
+	props := make(map[string][]string)
+	for {
+		p.skipSpace()
+		var ident []byte
+		start := p.i
+		for ; p.i < len(p.data) && (p.data[p.i] >= 'A' && p.data[p.i] <= 'Z' || p.data[p.i] >= 'a' && p.data[p.i] <= 'z'); p.i++ {
+			if c := p.data[p.i]; c >= 'A' && c <= 'Z' {
+				ident = append(ident, c)
+			}
+		}
+		if p.i == start {
+			return props, nil
+		}
+		p.skipSpace()
+		if len(ident) == 0 || p.i >= len(p.data) || p.data[p.i] != '[' {
+			return nil, fmt.Errorf("sgf: invalid property %q at offset %d", p.data[start:p.i], start)
+		}
+		name := string(ident)
+		for ; p.i < len(p.data) && p.data[p.i] == '['; p.skipSpace() {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			props[name] = append(props[name], v)
+		}
+	}
+}
+
+// 从'['读到']'，反斜杠转义下一个字符，反斜杠后面的换行去掉
+func (p *sgfParser) value() (string, error) {
+	start := p.i
+	var v []byte
+	for p.i++; p.i < len(p.data); p.i++ {
+		switch c := p.data[p.i]; c {
+		case ']':
+			p.i++
+			return string(v), nil
+		case '\\':
+			if p.i++; p.i >= len(p.data) {
+				return "", fmt.Errorf("sgf: unterminated value at offset %d", start)
+			}
+			switch p.data[p.i] {
+			case '\r':
+				if p.i+1 < len(p.data) && p.data[p.i+1] == '\n' {
+					p.i++
+				}
+			case '\n':
+			default:
+				v = append(v, p.data[p.i])
+			}
+		default:
+			v = append(v, c)
+		}
+	}
+	return "", fmt.Errorf("sgf: unterminated value at offset %d", start)
+}
+
+func newSGFTree(st *sgfTree) (*Tree, error) {
+	t := &Tree{Info: Record{Rule: board.Freestyle, Size: 15}, Root: &Node{}}
+	if err := t.Info.setSGFInfo(st.nodes[0]); err != nil {
+		return nil, err
+	}
+	if err := t.buildSGF(st, t.Root); err != nil {
+		return nil, err
+	}
+	// SGF只记让了几个子，哪一方让子从主变里第一次连下两步的一方看出来
+	if t.Info.Handicap > 0 {
+		moves := t.MainLine().Moves
+		for i := 1; i < len(moves); i++ {
+			if moves[i].Color == moves[i-1].Color {
+				t.Info.HandicapColor = moves[i].Color
+				break
+			}
+		}
+	}
+	return t, nil
+}
+
+// 读根节点中的对局信息，读过的属性从props中删掉
+func (r *Record) setSGFInfo(props map[string][]string) error {
+	if gm, ok := props["GM"]; ok && gm[0] != "4" {
+		return fmt.Errorf("GM[%s] is not gomoku or renju", gm[0])
+	}
+	if sz, ok := props["SZ"]; ok {
+		size, err := strconv.Atoi(sz[0])
+		if err != nil || size < 1 || size > 52 {
+			return fmt.Errorf("invalid board size SZ[%s]", sz[0])
+		}
+		r.Size = size
+	}
+	if ru, ok := props["RU"]; ok {
+		rule, err := board.ParseRule(strings.ToLower(strings.TrimSpace(ru[0])))
+		if err != nil {
+			rule = rifRule(ru[0])
+		}
+		r.Rule = rule
+	}
+	for key, field := range map[string]*string{"PB": &r.Black, "PW": &r.White, "EV": &r.Event, "ON": &r.Opening} {
+		if v, ok := props[key]; ok {
+			*field = v[0]
+		}
+	}
+	if dt, ok := props["DT"]; ok && len(dt[0]) >= 10 {
+		if d, err := time.Parse("2006-01-02", dt[0][:10]); err == nil {
+			r.Date = d
+		}
+	}
+	if tm, ok := props["TM"]; ok {
+		if sec, err := strconv.ParseFloat(tm[0], 64); err == nil {
+			r.Time = Duration(time.Duration(sec * float64(time.Second)))
+		}
+	}
+	if ot, ok := props["OT"]; ok {
+		if s, ok := strings.CutPrefix(ot[0], "increment "); ok {
+			if d, err := time.ParseDuration(s); err == nil {
+				r.Increment = Duration(d)
+			}
+		}
+	}
+	if ha, ok := props["HA"]; ok {
+		r.Handicap, _ = strconv.Atoi(ha[0])
+	}
+	if re, ok := props["RE"]; ok {
+		r.Winner, r.Reason = parseSGFResult(re[0])
+	}
+	for _, key := range []string{"GM", "FF", "CA", "AP", "SZ", "RU", "PB", "PW", "EV", "ON", "DT", "TM", "OT", "HA", "RE"} {
+		delete(props, key)
+	}
+	return nil
+}
+
+// SGF的结果写作"B+R"（认输）、"W+T"（超时）、"B+F"（犯规判负）、"B+"、"0"（和棋）等
+func parseSGFResult(re string) (board.Color, string) {
+	re = strings.TrimSpace(re)
+	switch strings.ToLower(re) {
+	case "0", "draw", "jigo":
+		return board.Empty, "recorded"
+	}
+	var winner board.Color
+	switch {
+	case strings.HasPrefix(re, "B+"):
+		winner = board.Black
+	case strings.HasPrefix(re, "W+"):
+		winner = board.White
+	default:
+		return board.Empty, ""
+	}
+	switch strings.ToUpper(re[2:]) {
+	case "R", "RESIGN":
+		return winner, "resign"
+	case "T", "TIME":
+		return winner, "time"
+	case "F", "FORFEIT":
+		return winner, "forfeit"
+	}
+	return winner, "recorded"
+}
+
+func sgfResult(winner board.Color, reason string) string {
+	if winner == board.Empty {
+		return "0"
+	}
+	re := "B+"
+	if winner == board.White {
+		re = "W+"
+	}
+	switch reason {
+	case "resign":
+		re += "R"
+	case "time":
+		re += "T"
+	case "forfeit", "illegal move", "quit":
+		re += "F"
+	}
+	return re
+}
+
+func (t *Tree) buildSGF(st *sgfTree, n *Node) error {
+	for _, props := range st.nodes {
+		var err error
+		if n, err = t.sgfNode(n, props); err != nil {
+			return err
+		}
+	}
+	for _, child := range st.children {
+		if err := t.buildSGF(child, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 节点里有着法时在parent下面新建一个节点，否则注释等属性都属于parent
+func (t *Tree) sgfNode(parent *Node, props map[string][]string) (*Node, error) {
+	n := parent
+	for _, color := range []board.Color{board.Black, board.White} {
+		key := sgfColor(color)
+		v, ok := props[key]
+		if !ok {
+			continue
+		}
+		if n != parent {
+			return nil, errors.New("node has both B and W")
+		}
+		p, err := parseSGFPoint(v[0], t.Info.Size)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%s]: %w", key, v[0], err)
+		}
+		n = &Node{Move: &Move{Color: color, P: p}}
+		parent.Children = append(parent.Children, n)
+	}
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := props[key]
+		switch key {
+		case "B", "W":
+		case "C":
+			n.Comment = strings.TrimSpace(values[0])
+		case "TE", "BM":
+			n.Annotation = map[string]string{"TE": "!", "BM": "?"}[key]
+			if values[0] == "2" {
+				n.Annotation += n.Annotation
+			}
+		case "IT":
+			n.Annotation = "!?"
+		case "DO":
+			n.Annotation = "?!"
+		case "V":
+			v, err := strconv.ParseFloat(values[0], 64)
+			if n.Move == nil || err != nil {
+				n.addExtra(key, values)
+				continue
+			}
+			eval := int(math.Round(v))
+			n.Move.Eval = &eval
+		case "TR", "CR", "SQ", "MA", "LB":
+			for _, v := range values {
+				label := ""
+				if key == "LB" {
+					v, label, _ = strings.Cut(v, ":")
+				}
+				points, err := parseSGFPoints(v, t.Info.Size)
+				if err != nil {
+					return nil, fmt.Errorf("%s[%s]: %w", key, v, err)
+				}
+				for _, p := range points {
+					n.Marks = append(n.Marks, Mark{P: p, Shape: key, Label: label})
+				}
+			}
+		case "AB", "AW", "AE":
+			return nil, fmt.Errorf("setup stones %s are not supported", key)
+		default:
+			n.addExtra(key, values)
+		}
+	}
+	return n, nil
+}
+
+func (n *Node) addExtra(key string, values []string) {
+	if n.Extra == nil {
+		n.Extra = make(map[string][]string)
+	}
+	n.Extra[key] = append(n.Extra[key], values...)
+}
+
+// WriteSGF 把开局树或者带变化的棋谱写成SGF，Children中第一个是主变
+func (t *Tree) WriteSGF(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	info := &t.Info
+	fmt.Fprintf(bw, "(;GM[4]FF[4]CA[UTF-8]AP[gobang]SZ[%d]RU[%s]", info.Size, info.Rule)
+	for _, prop := range [][2]string{{"PB", info.Black}, {"PW", info.White}, {"EV", info.Event}, {"ON", info.Opening}} {
+		if prop[1] != "" {
+			writeSGFProp(bw, prop[0], prop[1])
+		}
+	}
+	if !info.Date.IsZero() {
+		writeSGFProp(bw, "DT", info.Date.Format("2006-01-02"))
+	}
+	if info.Time > 0 {
+		writeSGFProp(bw, "TM", strconv.FormatFloat(time.Duration(info.Time).Seconds(), 'f', -1, 64))
+	}
+	if info.Increment > 0 {
+		writeSGFProp(bw, "OT", "increment "+time.Duration(info.Increment).String())
+	}
+	if info.Handicap > 0 {
+		writeSGFProp(bw, "HA", strconv.Itoa(info.Handicap))
+	}
+	if info.Reason != "" {
+		writeSGFProp(bw, "RE", sgfResult(info.Winner, info.Reason))
+	}
+	if err := writeSGFNode(bw, t.Root); err != nil {
+		return err
+	}
+	if err := writeSGFChildren(bw, t.Root); err != nil {
+		return err
+	}
+	bw.WriteString(")\n")
+	return bw.Flush()
+}
+
+// WriteSGF 把棋谱写成没有变化的SGF
+func (r *Record) WriteSGF(w io.Writer) error {
+	return r.Tree().WriteSGF(w)
+}
+
+// 只有一个子节点时接着写在同一串里，有多个时每个变化用括号括起来
+func writeSGFChildren(bw *bufio.Writer, n *Node) error {
+	for len(n.Children) == 1 {
+		n = n.Children[0]
+		bw.WriteString("\n;")
+		if err := writeSGFNode(bw, n); err != nil {
+			return err
+		}
+	}
+	for _, c := range n.Children {
+		bw.WriteString("\n(;")
+		if err := writeSGFNode(bw, c); err != nil {
+			return err
+		}
+		if err := writeSGFChildren(bw, c); err != nil {
+			return err
+		}
+		bw.WriteString(")")
+	}
+	return nil
+}
+
+func writeSGFNode(bw *bufio.Writer, n *Node) error {
+	if n.Move != nil {
+		if n.Move.Color != board.Black && n.Move.Color != board.White {
+			return fmt.Errorf("move %s has no color", n.Move.P)
+		}
+		writeSGFProp(bw, sgfColor(n.Move.Color), sgfPoint(n.Move.P))
+		if n.Move.Eval != nil {
+			writeSGFProp(bw, "V", strconv.Itoa(*n.Move.Eval))
+		}
+	}
+	if n.Annotation != "" {
+		prop, ok := sgfAnnotations[n.Annotation]
+		if !ok {
+			return fmt.Errorf("unknown annotation %q", n.Annotation)
+		}
+		bw.WriteString(prop)
+	}
+	if n.Comment != "" {
+		writeSGFProp(bw, "C", n.Comment)
+	}
+	for _, shape := range sgfShapes {
+		var values []string
+		for _, m := range n.Marks {
+			if m.Shape == shape && shape == "LB" {
+				values = append(values, sgfPoint(m.P)+":"+m.Label)
+			} else if m.Shape == shape {
+				values = append(values, sgfPoint(m.P))
+			}
+		}
+		if len(values) > 0 {
+			writeSGFProp(bw, shape, values...)
+		}
+	}
+	keys := make([]string, 0, len(n.Extra))
+	for key := range n.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeSGFProp(bw, key, n.Extra[key]...)
+	}
+	return nil
+}
+
+func writeSGFProp(bw *bufio.Writer, key string, values ...string) {
+	bw.WriteString(key)
+	for _, v := range values {
+		bw.WriteByte('[')
+		bw.WriteString(strings.NewReplacer(`\`, `\\`, `]`, `\]`).Replace(v))
+		bw.WriteByte(']')
+	}
+}
+
+func sgfColor(c board.Color) string {
+	if c == board.White {
+		return "W"
+	}
+	return "B"
+}
+
+// SGF的坐标先a到z，再A到Z
+const sgfLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+func sgfPoint(p board.Point) string {
+	return string([]byte{sgfLetters[p.X], sgfLetters[p.Y]})
+}
+
+func parseSGFPoint(s string, size int) (board.Point, error) {
+	if s == "" || s == "tt" && size <= 19 {
+		return board.Point{}, errors.New("pass is not supported")
+	}
+	if len(s) != 2 {
+		return board.Point{}, errors.New("invalid point")
+	}
+	p := board.Point{X: strings.IndexByte(sgfLetters, s[0]), Y: strings.IndexByte(sgfLetters, s[1])}
+	if p.X < 0 || p.X >= size || p.Y < 0 || p.Y >= size {
+		return board.Point{}, fmt.Errorf("point is out of the %dx%d board", size, size)
+	}
+	return p, nil
+}
+
+// 记号可以写成"aa:cc"表示一个矩形里的全部点
+func parseSGFPoints(s string, size int) ([]board.Point, error) {
+	first, last, compressed := strings.Cut(s, ":")
+	p1, err := parseSGFPoint(first, size)
+	if err != nil {
+		return nil, err
+	}
+	if !compressed {
+		return []board.Point{p1}, nil
+	}
+	p2, err := parseSGFPoint(last, size)
+	if err != nil {
+		return nil, err
+	}
+	var points []board.Point
+	for y := min(p1.Y, p2.Y); y <= max(p1.Y, p2.Y); y++ {
+		for x := min(p1.X, p2.X); x <= max(p1.X, p2.X); x++ {
+			points = append(points, board.Point{X: x, Y: y})
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,174 @@
+package record
+
+import (
+	"bytes"
+	"github.com/CuteReimu/gobang/board"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readOneSGF(t *testing.T, s string) *Tree {
+	t.Helper()
+	trees, err := ReadSGF(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("ReadSGF(%q): %v", s, err)
+	}
+	if len(trees) != 1 {
+		t.Fatalf("ReadSGF(%q) read %d games, want 1", s, len(trees))
+	}
+	return trees[0]
+}
+
+func TestSGFEscapes(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`plain`, "plain"},
+		{`a \] b`, "a ] b"},
+		{`back\\slash`, `back\slash`},
+		{`\:colon`, ":colon"},
+		{"soft\\\nbreak", "softbreak"},
+		{"soft\\\r\nbreak", "softbreak"},
+		{"hard\nbreak", "hard\nbreak"},
+	}
+	for _, tt := range tests {
+		tree := readOneSGF(t, "(;GM[4]SZ[15];B[hh]C["+tt.value+"])")
+		if got := tree.Root.Children[0].Comment; got != tt.want {
+			t.Errorf("C[%s] = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSGFVariations(t *testing.T) {
+	tree := readOneSGF(t, "(;GM[4]SZ[15]RE[B+R];B[hh](;W[ii];B[jj]C[main])(;W[gg])(;W[ig];B[gi]))")
+	var lines []string
+	for _, rec := range tree.Records() {
+		var sb strings.Builder
+		for _, m := range rec.Moves {
+			sb.WriteString(sgfColor(m.Color) + sgfPoint(m.P) + " ")
+		}
+		sb.WriteString(rec.Reason)
+		lines = append(lines, sb.String())
+	}
+	want := []string{"Bhh Wii Bjj resign", "Bhh Wgg ", "Bhh Wig Bgi "}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("variations = %q, want %q", lines, want)
+	}
+	if c := tree.MainLine(); len(c.Moves) != 3 || tree.Root.Children[0].Children[0].Children[0].Comment != "main" {
+		t.Errorf("main line = %v", c.Moves)
+	}
+}
+
+func TestSGFPoints(t *testing.T) {
+	tests := []struct {
+		value string
+		size  int
+		want  []board.Point
+		ok    bool
+	}{
+		{"aa", 15, []board.Point{{X: 0, Y: 0}}, true},
+		{"oo", 15, []board.Point{{X: 14, Y: 14}}, true},
+		{"aa:bb", 15, []board.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}, true},
+		{"cb:ab", 15, []board.Point{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}, true},
+		{"hh:hh", 15, []board.Point{{X: 7, Y: 7}}, true},
+		{"tt", 20, []board.Point{{X: 19, Y: 19}}, true},
+		{"AA", 52, []board.Point{{X: 26, Y: 26}}, true},
+		{"tt", 15, nil, false},
+		{"", 15, nil, false},
+		{"pa", 15, nil, false},
+		{"a", 15, nil, false},
+		{"aa:pp", 15, nil, false},
+	}
+	for _, tt := range tests {
+		got, err := parseSGFPoints(tt.value, tt.size)
+		if (err == nil) != tt.ok || tt.ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSGFPoints(%q, %d) = %v, %v; want %v, ok %t", tt.value, tt.size, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSGFMoves(t *testing.T) {
+	tests := []struct {
+		sgf string
+		ok  bool
+	}{
+		{"(;GM[4]SZ[15];B[hh];W[ii])", true},
+		{"(;GM[4]SZ[20];B[tt])", true},
+		{"(;GM[4]SZ[15];B[hh];W[tt])", false}, // 停一手
+		{"(;GM[4]SZ[15];B[])", false},
+		{"(;GM[4]SZ[15];B[hh]W[ii])", false},
+		{"(;GM[4]SZ[15]AB[hh];W[ii])", false},
+		{"(;GM[1]SZ[19];B[hh])", false},
+		{"(;GM[4]SZ[15];B[hh]", false},
+		{"(;GM[4]SZ[15];B[hh)", false},
+		{"(;GM[4]SZ[15](;B[hh]);W[ii])", false},
+	}
+	for _, tt := range tests {
+		_, err := ReadSGF(strings.NewReader(tt.sgf))
+		if (err == nil) != tt.ok {
+			t.Errorf("ReadSGF(%q) error %v, want ok %t", tt.sgf, err, tt.ok)
+		}
+	}
+}
+
+func TestSGFResult(t *testing.T) {
+	tests := []struct {
+		re     string
+		winner board.Color
+		reason string
+	}{
+		{"B+R", board.Black, "resign"},
+		{"W+Resign", board.White, "resign"},
+		{"W+T", board.White, "time"},
+		{"B+F", board.Black, "forfeit"},
+		{"B+", board.Black, "recorded"},
+		{"0", board.Empty, "recorded"},
+		{"Draw", board.Empty, "recorded"},
+		{"?", board.Empty, ""},
+	}
+	for _, tt := range tests {
+		if winner, reason := parseSGFResult(tt.re); winner != tt.winner || reason != tt.reason {
+			t.Errorf("parseSGFResult(%q) = %v, %q; want %v, %q", tt.re, winner, reason, tt.winner, tt.reason)
+		}
+	}
+}
+
+func TestSGFRoundTrip(t *testing.T) {
+	eval := -35
+	tree := &Tree{
+		Info: Record{
+			Black: "Alice [A]", White: `Bob \ B`, Event: "cup", Rule: board.Renju, Size: 15,
+			Date: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), Time: Duration(10 * time.Minute), Increment: Duration(5 * time.Second),
+			Opening: "kagetsu", Winner: board.White, Reason: "time",
+		},
+		Root: &Node{Comment: "root comment", Marks: []Mark{{P: board.Point{X: 7, Y: 7}, Shape: "SQ"}}},
+	}
+	h8 := &Node{Move: &Move{Color: board.Black, P: board.Point{X: 7, Y: 7}}, Annotation: "!!"}
+	i9 := &Node{Move: &Move{Color: board.White, P: board.Point{X: 8, Y: 6}, Eval: &eval}, Comment: "multi\nline ] comment",
+		Marks: []Mark{{P: board.Point{X: 1, Y: 0}, Shape: "LB", Label: "A:B"}, {P: board.Point{X: 0, Y: 0}, Shape: "TR"}}}
+	g9 := &Node{Move: &Move{Color: board.White, P: board.Point{X: 6, Y: 6}}, Annotation: "?!", Extra: map[string][]string{"XX": {"1", "2"}}}
+	j10 := &Node{Move: &Move{Color: board.Black, P: board.Point{X: 9, Y: 5}}}
+	tree.Root.Children = []*Node{h8}
+	h8.Children = []*Node{i9, g9}
+	i9.Children = []*Node{j10}
+
+	// 读的时候同一节点的属性按名字排序，所以Marks也按Shape排好
+	var buf bytes.Buffer
+	if err := tree.WriteSGF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := readOneSGF(t, buf.String())
+	if !reflect.DeepEqual(got, tree) {
+		t.Errorf("round trip changed the tree\nwrote %s\ngot  %+v\nwant %+v", buf.String(), got, tree)
+	}
+}
+
+func TestSGFHandicapColor(t *testing.T) {
+	tree := readOneSGF(t, "(;GM[4]SZ[15]HA[1];B[hh];W[ii];W[jj];B[gg])")
+	if tree.Info.Handicap != 1 || tree.Info.HandicapColor != board.White {
+		t.Errorf("handicap = %d %v, want 1 for white", tree.Info.Handicap, tree.Info.HandicapColor)
+	}
+}
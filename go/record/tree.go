@@ -0,0 +1,120 @@
+package record
+
+import (
+	"github.com/CuteReimu/gobang/board"
+)
+
+// Tree 是开局树或者带变化的棋谱
+type Tree struct {
+	Info Record // 对局信息，其中的Moves不用，着法都在Root下面
+	Root *Node
+}
+
+// Node 是Tree中的一个节点。根节点没有着法，Children中第一个是主变
+type Node struct {
+	Move       *Move // 根节点为nil
+	Comment    string
+	Annotation string              // 对这一步的评价："!"好棋、"!!"妙手、"?"坏棋、"??"败着、"!?"有意思、"?!"可疑
+	Marks      []Mark              // 标在棋盘上的记号
+	Extra      map[string][]string // 读SGF时不认识的属性，写SGF时原样写回
+	Children   []*Node
+}
+
+// Mark 是标在棋盘上的记号，Shape是SGF的属性名：TR三角、CR圆、SQ方块、MA叉、LB文字（写在Label里）
+type Mark struct {
+	P     board.Point
+	Shape string
+	Label string
+}
+
+// NewTree 把多局棋谱合并成一棵树，相同的着法序列共用节点，对局信息取第一局的规则和棋盘大小
+func NewTree(records []*Record) *Tree {
+	t := &Tree{Root: &Node{}}
+	if len(records) > 0 {
+		t.Info.Rule, t.Info.Size = records[0].Rule, records[0].Size
+	}
+	for _, rec := range records {
+		node := t.Root
+		for _, m := range rec.Moves {
+			node = node.child(m)
+		}
+	}
+	return t
+}
+
+// Records 把每个变化都当作一局返回，主变在最前面。对局结果只属于主变，其它变化没有结果
+func (t *Tree) Records() []*Record {
+	var records []*Record
+	for i, line := range t.Root.Lines() {
+		r := t.Info
+		r.Moves = line
+		if i > 0 {
+			r.Winner, r.Reason = board.Empty, ""
+		}
+		records = append(records, &r)
+	}
+	return records
+}
+
+// Tree 把棋谱变成只有一个变化的树，对局信息不变
+func (r *Record) Tree() *Tree {
+	t := NewTree([]*Record{r})
+	t.Info = *r
+	t.Info.Moves = nil
+	return t
+}
+
+// MainLine 返回一直走第一个子节点得到的棋谱
+func (t *Tree) MainLine() *Record {
+	rec := t.Info
+	rec.Moves = nil
+	for n := t.Root; len(n.Children) > 0; {
+		n = n.Children[0]
+		rec.Moves = append(rec.Moves, *n.Move)
+	}
+	return &rec
+}
+
+// 返回着法是m的子节点，没有的话新建一个
+func (n *Node) child(m Move) *Node {
+	for _, c := range n.Children {
+		if c.Move.Color == m.Color && c.Move.P == m.P {
+			return c
+		}
+	}
+	c := &Node{Move: &m}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// Find 从n开始沿着points依次往下找，找不到时返回nil
+func (n *Node) Find(points []board.Point) *Node {
+	for _, p := range points {
+		var next *Node
+		for _, c := range n.Children {
+			if c.Move.P == p {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+// Lines 返回从n到每个叶子节点的着法序列，不包括n自己的着法
+func (n *Node) Lines() [][]Move {
+	if len(n.Children) == 0 {
+		return [][]Move{nil}
+	}
+	var lines [][]Move
+	for _, c := range n.Children {
+		for _, line := range c.Lines() {
+			lines = append(lines, append([]Move{*c.Move}, line...))
+		}
+	}
+	return lines
+}
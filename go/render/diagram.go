@@ -0,0 +1,216 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/record"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Diagram 是要画成图片的一个局面，棋盘大小是board.Size
+type Diagram struct {
+	Moves      []record.Move // 棋盘上的棋子，按落子的顺序
+	Numbers    bool          // 在棋子上写出是第几步
+	LastMove   bool          // 标出最后一步
+	WinLine    []board.Point // 连成五的棋子，用红线连起来
+	Marks      []record.Mark // 记号和文字标签
+	Annotation string        // 对最后一步的评价，例如"!"，写在最后一步的右上方
+}
+
+// NewDiagram 返回棋谱rec下到第n步的局面，标出最后一步，最后一步连成五时画出这条线
+func NewDiagram(rec *record.Record, n int) *Diagram {
+	d := &Diagram{Moves: rec.Moves[:n], LastMove: true}
+	if n > 0 {
+		b := make([][]board.Color, board.Size)
+		for i := range b {
+			b[i] = make([]board.Color, board.Size)
+		}
+		for _, m := range d.Moves {
+			b[m.P.Y][m.P.X] = m.Color
+		}
+		d.WinLine = rec.Rule.WinningLine(b, d.Moves[n-1].P)
+	}
+	return d
+}
+
+// Frames 返回t的主变从空棋盘开始每一步的局面，带着每个节点上的记号和评价
+func Frames(t *record.Tree) []*Diagram {
+	rec := t.MainLine()
+	frames := []*Diagram{NewDiagram(rec, 0)}
+	frames[0].Marks = t.Root.Marks
+	n := t.Root
+	for i := range rec.Moves {
+		n = n.Children[0]
+		d := NewDiagram(rec, i+1)
+		d.Marks, d.Annotation = n.Marks, n.Annotation
+		frames = append(frames, d)
+	}
+	return frames
+}
+
+// 交叉点p的中心在图片上的坐标
+func center(p board.Point) (int, int) {
+	return Cell * (p.X + 1), Cell * (p.Y + 1)
+}
+
+// Image 把局面画成图片，和窗口里的棋盘一样大，四周标着坐标
+func (d *Diagram) Image() *image.RGBA {
+	size := board.Size
+	img := image.NewRGBA(image.Rect(0, 0, Cell*(size+1), Cell*(size+1)))
+	draw.Draw(img, img.Bounds(), image.NewUniform(BoardColor), image.Point{}, draw.Src)
+	for i := 1; i <= size; i++ {
+		for k := Cell; k <= Cell*size; k++ {
+			img.Set(k, Cell*i, lineColor)
+			img.Set(Cell*i, k, lineColor)
+		}
+	}
+	for i := 0; i < size; i++ {
+		row := strconv.Itoa(size - i)
+		w, h := textSize(row, 2)
+		drawText(img, row, Cell/2-w, Cell*(i+1)-h/2, 2, lineColor)
+		drawTextCentered(img, string(rune('a'+i)), Cell*(i+1), Cell*size+Cell/2+6, 2, lineColor)
+	}
+	occupied := make(map[board.Point]board.Color)
+	for i, m := range d.Moves {
+		occupied[m.P] = m.Color
+		x, y := center(m.P)
+		r := image.Rect(x-StoneSize/2, y-StoneSize/2, x+StoneSize/2+1, y+StoneSize/2+1)
+		draw.Draw(img, r, Stone(m.Color, d.LastMove && i == len(d.Moves)-1), image.Point{}, draw.Src)
+	}
+	if len(d.WinLine) >= 2 { // 画在步数下面，不挡住步数
+		x0, y0 := center(d.WinLine[0])
+		x1, y1 := center(d.WinLine[len(d.WinLine)-1])
+		drawLine(img, x0, y0, x1, y1, winColor)
+	}
+	if d.Numbers {
+		for i, m := range d.Moves {
+			var c color.Color = color.White
+			if m.Color == board.White {
+				c = color.Black
+			}
+			x, y := center(m.P)
+			drawTextCentered(img, strconv.Itoa(i+1), x, y, 2, c)
+		}
+	}
+	for _, m := range d.Marks {
+		drawMark(img, m, occupied[m.P])
+	}
+	if d.Annotation != "" && len(d.Moves) > 0 {
+		x, y := center(d.Moves[len(d.Moves)-1].P)
+		drawText(img, d.Annotation, x+10, y-Cell/2-4, 2, winColor)
+	}
+	return img
+}
+
+// 画一条3个像素宽的线
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	steps := max(abs(x1-x0), abs(y1-y0), 1)
+	for i := 0; i <= steps; i++ {
+		x, y := x0+(x1-x0)*i/steps, y0+(y1-y0)*i/steps
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				img.Set(x+dx, y+dy, c)
+			}
+		}
+	}
+}
+
+// 画出SGF的记号，文字标签写在空的交叉点上时先把线擦掉
+func drawMark(img *image.RGBA, m record.Mark, stone board.Color) {
+	x, y := center(m.P)
+	switch m.Shape {
+	case "TR":
+		drawLine(img, x, y-8, x-8, y+6, markColor)
+		drawLine(img, x-8, y+6, x+8, y+6, markColor)
+		drawLine(img, x+8, y+6, x, y-8, markColor)
+	case "SQ":
+		drawLine(img, x-7, y-7, x+7, y-7, markColor)
+		drawLine(img, x+7, y-7, x+7, y+7, markColor)
+		drawLine(img, x+7, y+7, x-7, y+7, markColor)
+		drawLine(img, x-7, y+7, x-7, y-7, markColor)
+	case "MA":
+		drawLine(img, x-7, y-7, x+7, y+7, markColor)
+		drawLine(img, x-7, y+7, x+7, y-7, markColor)
+	case "CR":
+		for dx := -10; dx <= 10; dx++ {
+			for dy := -10; dy <= 10; dy++ {
+				if r := dx*dx + dy*dy; r >= 7*7 && r <= 9*9 {
+					img.Set(x+dx, y+dy, markColor)
+				}
+			}
+		}
+	case "LB":
+		if stone == board.Empty {
+			w, h := textSize(m.Label, 2)
+			r := image.Rect(x-w/2-2, y-h/2-2, x+w/2+3, y+h/2+3)
+			draw.Draw(img, r, image.NewUniform(BoardColor), image.Point{}, draw.Src)
+		}
+		drawTextCentered(img, m.Label, x, y, 2, markColor)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// WritePNG 把局面写成PNG图片
+func (d *Diagram) WritePNG(w io.Writer) error {
+	return png.Encode(w, d.Image())
+}
+
+// WriteGIF 把每个局面作为一帧写成GIF动画，每帧停留delay，最后一帧多停一会
+func WriteGIF(w io.Writer, frames []*Diagram, delay time.Duration) error {
+	if len(frames) == 0 {
+		return errors.New("no frame to write")
+	}
+	palette := color.Palette{BoardColor, color.Black, color.White, winColor, markColor}
+	anim := &gif.GIF{}
+	for _, d := range frames {
+		img := d.Image()
+		p := image.NewPaletted(img.Bounds(), palette)
+		draw.Draw(p, p.Bounds(), img, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, p)
+		anim.Delay = append(anim.Delay, int(delay/(10*time.Millisecond))) // GIF的时间单位是10毫秒
+	}
+	anim.Delay[len(anim.Delay)-1] *= 3
+	return gif.EncodeAll(w, anim)
+}
+
+// Save 按扩展名把局面写成图片：.png只画最后一个局面，.gif把全部局面画成动画
+func Save(name string, frames []*Diagram, delay time.Duration) error {
+	if len(frames) == 0 {
+		return errors.New("no position to save")
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext != ".png" && ext != ".gif" {
+		return fmt.Errorf("%s: unknown image format %q, want .png or .gif", name, ext)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if ext == ".png" {
+		err = frames[len(frames)-1].WritePNG(f)
+	} else {
+		err = WriteGIF(f, frames, delay)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
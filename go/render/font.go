@@ -0,0 +1,63 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"unicode"
+)
+
+// 3x5的点阵字体，每个字符按行从上到下写出15个点，小写字母画成大写
+var glyphs = map[rune]string{
+	'0': "111101101101111", '1': "010110010010111", '2': "111001111100111", '3': "111001111001111",
+	'4': "101101111001001", '5': "111100111001111", '6': "111100111101111", '7': "111001001001001",
+	'8': "111101111101111", '9': "111101111001111",
+	'A': "010101111101101", 'B': "110101110101110", 'C': "011100100100011", 'D': "110101101101110",
+	'E': "111100110100111", 'F': "111100110100100", 'G': "011100101101011", 'H': "101101111101101",
+	'I': "111010010010111", 'J': "001001001101010", 'K': "101101110101101", 'L': "100100100100111",
+	'M': "101111111101101", 'N': "110101101101101", 'O': "010101101101010", 'P': "110101110100100",
+	'Q': "010101101110011", 'R': "110101110101101", 'S': "011100010001110", 'T': "111010010010010",
+	'U': "101101101101111", 'V': "101101101101010", 'W': "101101111111101", 'X': "101101010101101",
+	'Y': "101101010010010", 'Z': "111001010100111",
+	'!': "010010010000010", '?': "110001010000010", '-': "000000111000000", '+': "000010111010000",
+	'.': "000000000000010", ' ': "000000000000000",
+}
+
+// 每个字符的宽和高，画的时候放大scale倍，字符之间空1个像素
+const glyphWidth, glyphHeight = 3, 5
+
+// 按scale倍画出s的宽和高
+func textSize(s string, scale int) (int, int) {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0, 0
+	}
+	return n*glyphWidth*scale + (n - 1), glyphHeight * scale
+}
+
+// 从左上角(x,y)开始按scale倍画出s，不认识的字符画成问号
+func drawText(img *image.RGBA, s string, x, y, scale int, c color.Color) {
+	for _, r := range s {
+		g, ok := glyphs[unicode.ToUpper(r)]
+		if !ok {
+			g = glyphs['?']
+		}
+		for i, dot := range g {
+			if dot != '1' {
+				continue
+			}
+			gx, gy := x+i%glyphWidth*scale, y+i/glyphWidth*scale
+			for dx := range scale {
+				for dy := range scale {
+					img.Set(gx+dx, gy+dy, c)
+				}
+			}
+		}
+		x += glyphWidth*scale + 1
+	}
+}
+
+// 以(cx,cy)为中心画出s
+func drawTextCentered(img *image.RGBA, s string, cx, cy, scale int, c color.Color) {
+	w, h := textSize(s, scale)
+	drawText(img, s, cx-w/2, cy-h/2, scale, c)
+}
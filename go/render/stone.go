@@ -0,0 +1,71 @@
+// Package render 不用窗口把局面画成图片，可以导出PNG棋图和GIF动画，ui的棋子图片也是这里画的。
+package render
+
+import (
+	"github.com/CuteReimu/gobang/board"
+	"image"
+	"image/color"
+	"math"
+)
+
+// 棋盘的底色和画图用的颜色
+var (
+	BoardColor = color.RGBA{R: 0xee, G: 0xd2, B: 0x5c, A: 0xff}
+	lineColor  = color.RGBA{A: 0xff}
+	winColor   = color.RGBA{R: 0xd0, G: 0x20, B: 0x20, A: 0xff}
+	markColor  = color.RGBA{R: 0x20, G: 0x40, B: 0xd0, A: 0xff}
+)
+
+// Cell 是相邻两条线的距离，棋盘四周各留一格
+const Cell = 35
+
+// StoneSize 是棋子图片的边长
+const StoneSize = 33
+
+var stones = make(map[bool]map[board.Color]*image.RGBA)
+
+func init() {
+	for _, last := range []bool{false, true} {
+		stones[last] = map[board.Color]*image.RGBA{
+			board.Black: image.NewRGBA(image.Rect(0, 0, StoneSize, StoneSize)),
+			board.White: image.NewRGBA(image.Rect(0, 0, StoneSize, StoneSize)),
+		}
+	}
+	pieceBlack, pieceWhite := stones[false][board.Black], stones[false][board.White]
+	pieceBlack2, pieceWhite2 := stones[true][board.Black], stones[true][board.White]
+	for _, img := range []*image.RGBA{pieceBlack, pieceWhite, pieceBlack2, pieceWhite2} {
+		for i := range StoneSize {
+			for j := range StoneSize {
+				img.Set(i, j, BoardColor)
+			}
+		}
+	}
+	for i := range StoneSize {
+		for j := range StoneSize {
+			diff := math.Sqrt(float64((i-16)*(i-16) + (j-16)*(j-16)))
+			if diff <= 16 {
+				pieceBlack.Set(i, j, color.Black)
+				pieceBlack2.Set(i, j, color.Black)
+				if diff > 14.5 {
+					pieceWhite.Set(i, j, color.Black)
+					pieceWhite2.Set(i, j, color.Black)
+				} else if diff > 13 {
+					pieceWhite.Set(i, j, color.White)
+					pieceWhite2.Set(i, j, color.White)
+					pieceBlack2.Set(i, j, color.White)
+				} else if diff > 11.5 {
+					pieceWhite.Set(i, j, color.White)
+					pieceWhite2.Set(i, j, color.Black)
+				} else {
+					pieceWhite.Set(i, j, color.White)
+					pieceWhite2.Set(i, j, color.White)
+				}
+			}
+		}
+	}
+}
+
+// Stone 返回color方棋子的图片，last为true时是标出最后一步的样子。返回的图片是共用的，不要修改
+func Stone(c board.Color, last bool) *image.RGBA {
+	return stones[last][c]
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/record"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BrowseTree 在终端里浏览开局树：显示当前局面和后续的着法，输入序号走下去，u回到上一步，t回到开头，q退出
+func BrowseTree(t *record.Tree, in *bufio.Scanner, out io.Writer) error {
+	path := []*record.Node{t.Root}
+	for {
+		node := path[len(path)-1]
+		b := make([][]board.Color, board.Size)
+		for i := range b {
+			b[i] = make([]board.Color, board.Size)
+		}
+		last := board.Point{X: -1, Y: -1}
+		for _, n := range path[1:] {
+			b[n.Move.P.Y][n.Move.P.X] = n.Move.Color
+			last = n.Move.P
+		}
+		renderBoard(out, b, last)
+		if node.Comment != "" {
+			fmt.Fprintln(out, node.Comment)
+		}
+		for i, c := range node.Children {
+			fmt.Fprintf(out, "%d. %s%s%s %s\n", i+1, c.Move.Color, c.Move.P, c.Annotation, firstLine(c.Comment))
+		}
+		fmt.Fprintf(out, "move %d> ", len(path)-1)
+		if !in.Scan() {
+			return in.Err()
+		}
+		switch s := strings.TrimSpace(in.Text()); s {
+		case "q":
+			return nil
+		case "u":
+			if len(path) > 1 {
+				path = path[:len(path)-1]
+			}
+		case "t":
+			path = path[:1]
+		default:
+			i, err := strconv.Atoi(s)
+			if err != nil || i < 1 || i > len(node.Children) {
+				fmt.Fprintf(out, "invalid input %q, want 1 to %d, u, t or q\n", s, len(node.Children))
+				continue
+			}
+			path = append(path, node.Children[i-1])
+		}
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
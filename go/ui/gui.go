@@ -0,0 +1,362 @@
+// Package ui 是人类玩家的界面，包括ebiten窗口和终端。
+package ui
+
+import (
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/engine"
+	"github.com/CuteReimu/gobang/game"
+	"github.com/CuteReimu/gobang/render"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"image/color"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// boardView 是HumanPlayer和HumanWatcher共用的棋盘显示部分，通过对局事件更新棋盘
+type boardView struct {
+	sync.Mutex
+	board    [][]board.Color
+	p        board.Point
+	info     string
+	status   string
+	notice   string     // 双方剩余时间或者不合法着法的提示
+	result   string     // 对局结果，结束后代替notice显示
+	rule     board.Rule // 由GameStarted得知，用来在落子前检查禁手
+	OnSave   func()     // 按S保存棋谱时调用，可以为nil
+	OnReview func()     // 对局结束后按V复盘时调用，可以为nil
+}
+
+func newBoardView() *boardView {
+	v := &boardView{
+		board: make([][]board.Color, board.Size),
+		p:     board.Point{X: -1, Y: -1},
+	}
+	for i := 0; i < board.Size; i++ {
+		v.board[i] = make([]board.Color, board.Size)
+	}
+	return v
+}
+
+// Notify 根据对局事件更新棋盘和提示文字，在对局的goroutine中调用
+func (v *boardView) Notify(e game.Event) {
+	v.Lock()
+	defer v.Unlock()
+	switch e := e.(type) {
+	case game.GameStarted:
+		v.rule = e.Rule
+		for _, row := range v.board {
+			clear(row)
+		}
+		v.p = board.Point{X: -1, Y: -1}
+		v.result = ""
+	case game.MovePlayed:
+		v.board[e.P.Y][e.P.X] = e.Color
+		v.p = e.P
+	case game.MoveUndone:
+		v.board[e.P.Y][e.P.X] = board.Empty
+		v.p = board.Point{X: -1, Y: -1}
+	case game.MoveRejected:
+		v.notice = "illegal move: " + e.Err.Error()
+	case game.GameOver:
+		v.result = fmt.Sprintf("winner: %s (%s)", e.Winner, e.Reason)
+	case game.ClockTick:
+		v.notice = fmt.Sprintf("%s %v  %s %v", board.Black, e.Black.Round(time.Second), board.White, e.White.Round(time.Second))
+	}
+}
+
+func (v *boardView) at(p board.Point) board.Color {
+	v.Lock()
+	defer v.Unlock()
+	return v.board[p.Y][p.X]
+}
+
+// 在p点落color方的子是否是禁手，在窗口里先检查，免得走出去被判不合法，用掉重走的次数
+func (v *boardView) forbidden(p board.Point, color board.Color) bool {
+	v.Lock()
+	defer v.Unlock()
+	return v.rule.IsForbidden(v.board, p, color)
+}
+
+// WindowSize 返回窗口的大小：棋盘四周各留一格放坐标，下面再留一行显示状态
+func WindowSize() (width, height int) {
+	return render.Cell * (board.Size + 1), render.Cell*(board.Size+1) + 16
+}
+
+func (v *boardView) Draw(screen *ebiten.Image) {
+	screen.Fill(render.BoardColor)
+	img0 := ebiten.NewImage(render.Cell*(board.Size+1), render.Cell*(board.Size+1))
+	img := ebiten.NewImage(render.Cell*(board.Size-1), 1)
+	img.Fill(color.Black)
+	opt := &ebiten.DrawImageOptions{}
+	opt.GeoM.Translate(render.Cell, render.Cell)
+	for range board.Size {
+		img0.DrawImage(img, opt)
+		opt.GeoM.Translate(0, render.Cell)
+	}
+	opt = &ebiten.DrawImageOptions{}
+	screen.DrawImage(img0, opt)
+	center := render.Cell * float64(board.Size+1) / 2
+	opt.GeoM.Translate(-center, -center)
+	opt.GeoM.Rotate(math.Pi / 2)
+	opt.GeoM.Translate(center, center)
+	screen.DrawImage(img0, opt)
+	drawCoordinates(screen)
+	v.Lock()
+	defer v.Unlock()
+	for y, row := range v.board {
+		for x, color := range row {
+			if color != board.Empty {
+				img := pieceBlack
+				if color == board.White {
+					img = pieceWhite
+				}
+				if v.p == (board.Point{X: x, Y: y}) {
+					img = pieceBlack2
+					if color == board.White {
+						img = pieceWhite2
+					}
+				}
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(render.Cell*(x+1)-render.StoneSize/2), float64(render.Cell*(y+1)-render.StoneSize/2))
+				screen.DrawImage(img, op)
+			}
+		}
+	}
+	ebitenutil.DebugPrintAt(screen, v.info, 4, 0)
+	if v.result != "" && v.OnReview != nil {
+		ebitenutil.DebugPrintAt(screen, v.result+", press V to review", 4, 16)
+	} else if v.result != "" {
+		ebitenutil.DebugPrintAt(screen, v.result, 4, 16)
+	} else {
+		ebitenutil.DebugPrintAt(screen, v.notice, 4, 16)
+	}
+	ebitenutil.DebugPrintAt(screen, v.status, 4, render.Cell*(board.Size+1))
+}
+
+// 鼠标的位置落在哪个交叉点的棋子范围内，就返回哪个点，没有落在任何一个点上时ok为false
+func cursorPoint(x, y int) (p board.Point, ok bool) {
+	p = board.Point{X: (x+render.Cell/2)/render.Cell - 1, Y: (y+render.Cell/2)/render.Cell - 1}
+	dx, dy := x-render.Cell*(p.X+1), y-render.Cell*(p.Y+1)
+	r := render.StoneSize / 2
+	return p, dx*dx+dy*dy <= r*r
+}
+
+// 在棋盘左边标出行号，下边标出列的字母，和Point.String一致
+func drawCoordinates(screen *ebiten.Image) {
+	for i := 0; i < board.Size; i++ {
+		row := strconv.Itoa(board.Size - i)
+		ebitenutil.DebugPrintAt(screen, row, render.Cell/2-6*len(row), render.Cell*(i+1)-8)
+		ebitenutil.DebugPrintAt(screen, string(rune('a'+i)), render.Cell*(i+1)-3, render.Cell*board.Size+render.Cell/2)
+	}
+}
+
+// SetInfo 设置窗口顶部显示的文字，可以在其它goroutine中调用
+func (v *boardView) SetInfo(info string) {
+	v.Lock()
+	v.info = info
+	v.Unlock()
+}
+
+// SetStatus 设置窗口底部显示的文字，可以在其它goroutine中调用
+func (v *boardView) SetStatus(status string) {
+	v.Lock()
+	v.status = status
+	v.Unlock()
+}
+
+func (v *boardView) Layout(int, int) (screenWidth int, screenHeight int) {
+	return WindowSize()
+}
+
+func (v *boardView) checkSave() {
+	if v.OnSave != nil && inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		v.OnSave()
+	}
+}
+
+func (v *boardView) checkReview() {
+	if v.OnReview == nil || !inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		return
+	}
+	v.Lock()
+	over := v.result != ""
+	v.Unlock()
+	if over {
+		v.OnReview()
+	}
+}
+
+func (v *boardView) setNotice(notice string) {
+	v.Lock()
+	v.notice = notice
+	v.Unlock()
+}
+
+// HumanPlayer 是用鼠标在窗口里下棋的人类玩家，同时也是ebiten.Game，负责画出棋盘。
+// 轮到自己时按R认输、按D提和、按L读取棋谱、按H换一个让子数重新开始，对方提和时按Y同意、按N拒绝，对局结束后按V复盘
+type HumanPlayer struct {
+	*boardView
+	isTurn       bool // 由boardView的锁保护，Play在对局的goroutine中设置，Update在ebiten的goroutine中读取
+	drawOffered  bool // 和isTurn一样由锁保护
+	pColor       board.Color
+	nextPoint    chan board.Point
+	action       chan error // 认输、提和、读取棋谱或超时被打断
+	drawAnswer   chan bool
+	OnDifficulty func(engine.Difficulty) // 按数字键1~4切换难度时调用，可以为nil
+	OnLoad       func() error            // 轮到自己时按L调用，在里面改变对局之后Play返回game.ErrInterrupted，可以为nil
+	OnHandicap   func()                  // 轮到自己时按H调用，在里面重新开始对局之后Play返回game.ErrInterrupted，可以为nil
+}
+
+var difficultyKeys = []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4}
+
+func (h *HumanPlayer) Update() error {
+	h.checkSave()
+	h.checkReview()
+	for i, key := range difficultyKeys {
+		if h.OnDifficulty != nil && inpututil.IsKeyJustPressed(key) {
+			h.OnDifficulty(engine.Difficulty(i))
+			h.SetStatus("difficulty: " + engine.Difficulty(i).String())
+		}
+	}
+	h.Lock()
+	isTurn, drawOffered := h.isTurn, h.drawOffered
+	h.Unlock()
+	if drawOffered {
+		if inpututil.IsKeyJustPressed(ebiten.KeyY) || inpututil.IsKeyJustPressed(ebiten.KeyN) {
+			h.setDrawOffered(false)
+			h.drawAnswer <- inpututil.IsKeyJustPressed(ebiten.KeyY)
+		}
+		return nil
+	}
+	if isTurn {
+		switch {
+		case inpututil.IsKeyJustPressed(ebiten.KeyR):
+			if h.takeTurn() {
+				h.action <- game.ErrResign
+			}
+			return nil
+		case inpututil.IsKeyJustPressed(ebiten.KeyD):
+			if h.takeTurn() {
+				h.action <- game.ErrDrawOffer
+			}
+			return nil
+		case h.OnLoad != nil && inpututil.IsKeyJustPressed(ebiten.KeyL):
+			if err := h.OnLoad(); err != nil {
+				h.SetStatus(err.Error())
+				return nil
+			}
+			if h.takeTurn() {
+				h.action <- game.ErrInterrupted
+			}
+			return nil
+		case h.OnHandicap != nil && inpututil.IsKeyJustPressed(ebiten.KeyH):
+			if h.takeTurn() {
+				h.OnHandicap()
+				h.action <- game.ErrInterrupted
+			}
+			return nil
+		}
+	}
+	if isTurn && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if p, ok := cursorPoint(ebiten.CursorPosition()); ok {
+			if p.CheckRange() && h.at(p) == board.Empty && h.forbidden(p, h.pColor) {
+				h.SetStatus(fmt.Sprintf("%s is forbidden for %s", p, h.pColor))
+			} else if p.CheckRange() && h.at(p) == board.Empty && h.takeTurn() {
+				h.nextPoint <- p // 棋子由随后的MovePlayed事件摆上
+			}
+		}
+	}
+	return nil
+}
+
+// NewHumanPlayer 创建执color的人类玩家
+func NewHumanPlayer(color board.Color) *HumanPlayer {
+	return &HumanPlayer{
+		boardView:  newBoardView(),
+		pColor:     color,
+		nextPoint:  make(chan board.Point),
+		action:     make(chan error),
+		drawAnswer: make(chan bool),
+	}
+}
+
+func (h *HumanPlayer) Color() board.Color {
+	return h.pColor
+}
+
+func (h *HumanPlayer) setTurn(isTurn bool) {
+	h.Lock()
+	h.isTurn = isTurn
+	h.Unlock()
+}
+
+// 还轮到自己时把isTurn清掉并返回true，之后只有调用者能把结果交给Play。
+// Update和Interrupt在不同的goroutine里，都要先拿到这一步，否则Play已经返回时另一方会卡在发送上
+func (h *HumanPlayer) takeTurn() bool {
+	h.Lock()
+	defer h.Unlock()
+	if !h.isTurn {
+		return false
+	}
+	h.isTurn = false
+	return true
+}
+
+func (h *HumanPlayer) setDrawOffered(offered bool) {
+	h.Lock()
+	h.drawOffered = offered
+	h.Unlock()
+}
+
+func (h *HumanPlayer) Play() (board.Point, error) {
+	h.setTurn(true)
+	select {
+	case p := <-h.nextPoint:
+		return p, nil
+	case err := <-h.action:
+		return board.Point{}, err
+	}
+}
+
+// Interrupt 在超时时收回这一步，正在等待的Play返回game.ErrInterrupted
+func (h *HumanPlayer) Interrupt() {
+	if h.takeTurn() {
+		h.action <- game.ErrInterrupted
+	}
+}
+
+// AcceptDraw 在窗口里提示对方提和，等待按Y或N
+func (h *HumanPlayer) AcceptDraw() bool {
+	h.setNotice("draw offered: press Y to accept, N to decline")
+	h.setDrawOffered(true)
+	return <-h.drawAnswer
+}
+
+// HumanWatcher 只显示棋盘，用于观看两个机器人对弈，订阅对局事件即可，也可以按S保存棋谱、结束后按V复盘
+type HumanWatcher struct {
+	*boardView
+}
+
+// NewHumanWatcher 创建空棋盘的观看窗口
+func NewHumanWatcher() *HumanWatcher {
+	return &HumanWatcher{newBoardView()}
+}
+
+func (h *HumanWatcher) Update() error {
+	h.checkSave()
+	h.checkReview()
+	return nil
+}
+
+// 棋子的图片和导出的棋图共用render画好的
+var (
+	pieceWhite  = ebiten.NewImageFromImage(render.Stone(board.White, false))
+	pieceBlack  = ebiten.NewImageFromImage(render.Stone(board.Black, false))
+	pieceWhite2 = ebiten.NewImageFromImage(render.Stone(board.White, true))
+	pieceBlack2 = ebiten.NewImageFromImage(render.Stone(board.Black, true))
+)
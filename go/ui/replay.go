@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/record"
+	"github.com/CuteReimu/gobang/render"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"strconv"
+	"sync"
+)
+
+// ReplayViewer 是复盘窗口，显示棋谱主变上的某一步。按←→后退前进一步，PageUp和PageDown一次走10步，
+// Home和End回到开头和最后，输入数字后按Enter跳到第几步，点一个棋子跳到下完这个子的时候，
+// 按N显示或隐藏步数，按B从当前局面开始和机器人下一局新的。已经分出结果的最后一步不能按B
+type ReplayViewer struct {
+	rec      *record.Record // 主变
+	frames   []*render.Diagram
+	n        int // 正在看第几步，0是空棋盘
+	numbers  bool
+	input    string                   // 正在输入的步数
+	image    *ebiten.Image            // 当前局面画好的图，为nil时重画
+	OnBranch func(rec *record.Record) // 按B时调用，rec是下到当前这一步、还没有结果的棋谱，可以为nil
+}
+
+// NewReplayViewer 创建复盘窗口，从t的最后一步开始看，棋盘大小必须已经设置成t.Info.Size
+func NewReplayViewer(t *record.Tree) *ReplayViewer {
+	v := &ReplayViewer{rec: t.MainLine(), frames: render.Frames(t)}
+	v.n = len(v.frames) - 1
+	return v
+}
+
+func (v *ReplayViewer) Update() error {
+	n := v.n
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		n++
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft):
+		n--
+	case inpututil.IsKeyJustPressed(ebiten.KeyPageDown):
+		n += 10
+	case inpututil.IsKeyJustPressed(ebiten.KeyPageUp):
+		n -= 10
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		n = 0
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		n = len(v.frames) - 1
+	case inpututil.IsKeyJustPressed(ebiten.KeyN):
+		v.numbers = !v.numbers
+		v.redraw()
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && v.input != "":
+		v.input = v.input[:len(v.input)-1]
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) && v.input != "":
+		n, _ = strconv.Atoi(v.input)
+		v.input = ""
+	case inpututil.IsKeyJustPressed(ebiten.KeyB) && v.canBranch():
+		rec := *v.rec
+		rec.Moves = append([]record.Move(nil), v.rec.Moves[:v.n]...)
+		rec.Winner, rec.Reason = board.Empty, ""
+		v.OnBranch(&rec)
+		return nil
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		if p, ok := cursorPoint(ebiten.CursorPosition()); ok {
+			for i, m := range v.rec.Moves {
+				if m.P == p {
+					n = i + 1
+				}
+			}
+		}
+	}
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r >= '0' && r <= '9' && len(v.input) < 3 {
+			v.input += string(r)
+		}
+	}
+	if n = max(0, min(n, len(v.frames)-1)); n != v.n {
+		v.n = n
+		v.redraw()
+	}
+	return nil
+}
+
+// 是否可以按B从当前局面接着下，对局结束时的最后一步已经没有可下的了
+func (v *ReplayViewer) canBranch() bool {
+	return v.OnBranch != nil && !(v.n == len(v.frames)-1 && v.rec.Reason != "")
+}
+
+// 局面或者步数的显示变了，下一帧重画
+func (v *ReplayViewer) redraw() {
+	if v.image != nil {
+		v.image.Deallocate()
+		v.image = nil
+	}
+}
+
+func (v *ReplayViewer) Draw(screen *ebiten.Image) {
+	if v.image == nil {
+		d := v.frames[v.n]
+		d.Numbers = v.numbers
+		v.image = ebiten.NewImageFromImage(d.Image())
+	}
+	screen.Fill(render.BoardColor)
+	screen.DrawImage(v.image, nil)
+	info := fmt.Sprintf("move %d/%d", v.n, len(v.frames)-1)
+	if v.n > 0 {
+		info += fmt.Sprintf(": %s%s", v.rec.Moves[v.n-1].P, v.frames[v.n].Annotation)
+	}
+	if v.n == len(v.frames)-1 && v.rec.Reason != "" {
+		info += fmt.Sprintf(", winner: %s (%s)", v.rec.Winner, v.rec.Reason)
+	}
+	ebitenutil.DebugPrintAt(screen, info, 4, 0)
+	status := "<- -> Home End, number+Enter: go to move, N: numbers"
+	if v.canBranch() {
+		status += ", B: play from here"
+	}
+	if v.input != "" {
+		status = "go to move " + v.input
+	}
+	ebitenutil.DebugPrintAt(screen, status, 4, render.Cell*(board.Size+1))
+}
+
+func (v *ReplayViewer) Layout(int, int) (screenWidth int, screenHeight int) {
+	return WindowSize()
+}
+
+// Switcher 是可以换成另一个画面的窗口，例如从复盘换到新的一局，可以在其它goroutine中调用Set
+type Switcher struct {
+	mu   sync.Mutex
+	game ebiten.Game
+}
+
+// NewSwitcher 创建一开始显示g的窗口
+func NewSwitcher(g ebiten.Game) *Switcher {
+	return &Switcher{game: g}
+}
+
+// Set 从下一帧开始显示g
+func (s *Switcher) Set(g ebiten.Game) {
+	s.mu.Lock()
+	s.game = g
+	s.mu.Unlock()
+}
+
+func (s *Switcher) current() ebiten.Game {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.game
+}
+
+func (s *Switcher) Update() error {
+	return s.current().Update()
+}
+
+func (s *Switcher) Draw(screen *ebiten.Image) {
+	s.current().Draw(screen)
+}
+
+func (s *Switcher) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return s.current().Layout(outsideWidth, outsideHeight)
+}
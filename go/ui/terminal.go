@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/CuteReimu/gobang/board"
+	"github.com/CuteReimu/gobang/game"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TerminalPlayer 是在终端里下棋的人类玩家，不需要图形界面
+type TerminalPlayer struct {
+	board  [][]board.Color
+	p      board.Point
+	pColor board.Color
+	rule   board.Rule
+	in     *bufio.Scanner
+	out    io.Writer
+
+	mu        sync.Mutex
+	playing   bool          // 由mu保护，只有在Play中才接受Interrupt
+	interrupt chan struct{} // 容量为1，Interrupt往里放一个值让Play返回
+}
+
+// NewTerminalPlayer 创建终端玩家，两个终端玩家可以共用同一个in
+func NewTerminalPlayer(color board.Color, in *bufio.Scanner, out io.Writer) *TerminalPlayer {
+	tp := &TerminalPlayer{
+		board:     make([][]board.Color, board.Size),
+		p:         board.Point{X: -1, Y: -1},
+		pColor:    color,
+		in:        in,
+		out:       out,
+		interrupt: make(chan struct{}, 1),
+	}
+	for i := 0; i < board.Size; i++ {
+		tp.board[i] = make([]board.Color, board.Size)
+	}
+	return tp
+}
+
+func (t *TerminalPlayer) Color() board.Color {
+	return t.pColor
+}
+
+func (t *TerminalPlayer) Play() (board.Point, error) {
+	t.setPlaying(true)
+	defer t.setPlaying(false)
+	t.Render()
+	for {
+		fmt.Fprintf(t.out, "%s> ", t.pColor)
+		text, err := t.readLine()
+		if err != nil {
+			return board.Point{}, err
+		}
+		switch strings.TrimSpace(text) {
+		case "resign":
+			return board.Point{}, game.ErrResign
+		case "draw":
+			return board.Point{}, game.ErrDrawOffer
+		case "position":
+			fmt.Fprintln(t.out, board.PositionOf(t.board, t.rule, t.pColor))
+			continue
+		}
+		p, err := parseTerminalPoint(text)
+		if err != nil {
+			fmt.Fprintln(t.out, err)
+			continue
+		}
+		if t.board[p.Y][p.X] != board.Empty {
+			fmt.Fprintf(t.out, "%s is occupied\n", p)
+			continue
+		}
+		if t.rule.IsForbidden(t.board, p, t.pColor) {
+			fmt.Fprintf(t.out, "%s is forbidden for %s under %s rules\n", p, t.pColor, t.rule)
+			continue
+		}
+		return p, nil
+	}
+}
+
+// 进入Play时清掉上一步返回之后才到的Interrupt
+func (t *TerminalPlayer) setPlaying(playing bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.playing = playing
+	select {
+	case <-t.interrupt:
+	default:
+	}
+}
+
+// 在另一个goroutine里读一行，被Interrupt打断时不再等待，返回game.ErrInterrupted。
+// 那个goroutine还会读走下一行输入，所以只在对局因超时结束时打断
+func (t *TerminalPlayer) readLine() (string, error) {
+	type line struct {
+		text string
+		err  error
+	}
+	c := make(chan line, 1)
+	go func() {
+		if !t.in.Scan() {
+			err := t.in.Err()
+			if err == nil {
+				err = io.EOF
+			}
+			c <- line{err: err}
+			return
+		}
+		c <- line{text: t.in.Text()}
+	}()
+	select {
+	case l := <-c:
+		return l.text, l.err
+	case <-t.interrupt:
+		fmt.Fprintln(t.out)
+		return "", game.ErrInterrupted
+	}
+}
+
+// Interrupt 在超时时收回这一步，正在等待输入的Play返回game.ErrInterrupted
+func (t *TerminalPlayer) Interrupt() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.playing {
+		return
+	}
+	select {
+	case t.interrupt <- struct{}{}:
+	default:
+	}
+}
+
+// AcceptDraw 在终端询问是否同意对方的提和
+func (t *TerminalPlayer) AcceptDraw() bool {
+	for {
+		fmt.Fprintf(t.out, "%s, %s offers a draw, accept? [y/n] ", t.pColor, t.pColor.Conversion())
+		if !t.in.Scan() {
+			return false
+		}
+		switch strings.ToLower(strings.TrimSpace(t.in.Text())) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
+
+// Notify 根据对局事件更新自己的棋盘
+func (t *TerminalPlayer) Notify(e game.Event) {
+	switch e := e.(type) {
+	case game.GameStarted:
+		t.rule = e.Rule
+		for _, row := range t.board {
+			clear(row)
+		}
+		t.p = board.Point{X: -1, Y: -1}
+	case game.MovePlayed:
+		t.board[e.P.Y][e.P.X] = e.Color
+		t.p = e.P
+	case game.MoveUndone:
+		t.board[e.P.Y][e.P.X] = board.Empty
+		t.p = board.Point{X: -1, Y: -1}
+	}
+}
+
+// 输入格式为"h8"，也可以是从0开始的"x y"或"x,y"，认输、提和以及打印局面在Play中处理
+func parseTerminalPoint(s string) (board.Point, error) {
+	s = strings.TrimSpace(s)
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '(' || r == ')'
+	})
+	if len(fields) != 2 {
+		p, err := board.ParseNotation(s, board.Size)
+		if err != nil {
+			return board.Point{}, fmt.Errorf("invalid input %q, want a point like %s", s, board.Point{X: board.Size / 2, Y: board.Size / 2})
+		}
+		return p, nil
+	}
+	x, err1 := strconv.Atoi(fields[0])
+	y, err2 := strconv.Atoi(fields[1])
+	p := board.Point{X: x, Y: y}
+	if err1 != nil || err2 != nil || !p.CheckRange() {
+		return board.Point{}, fmt.Errorf("invalid input %q, want x,y between 0 and %d", s, board.Size-1)
+	}
+	return p, nil
+}
+
+// Render 把棋盘画到out，最后一步棋用LastSymbol的符号标出
+func (t *TerminalPlayer) Render() {
+	renderBoard(t.out, t.board, t.p)
+}
+
+// 把棋盘b画到out，last用LastSymbol的符号标出。列用字母标出，行号从下往上数，和Point.String一致
+func renderBoard(out io.Writer, b [][]board.Color, last board.Point) {
+	var sb strings.Builder
+	sb.WriteString("   ")
+	for x := 0; x < board.Size; x++ {
+		fmt.Fprintf(&sb, "%c ", 'a'+x)
+	}
+	sb.WriteString("\n")
+	for y, row := range b {
+		fmt.Fprintf(&sb, "%2d ", board.Size-y)
+		for x, color := range row {
+			switch {
+			case color == board.Empty:
+				sb.WriteString("+")
+			case last == board.Point{X: x, Y: y}:
+				sb.WriteString(color.LastSymbol())
+			default:
+				sb.WriteString(color.Symbol())
+			}
+			sb.WriteString(" ")
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprint(out, sb.String())
+}